@@ -0,0 +1,108 @@
+package telebot
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/3JoB/ulib/litefmt"
+)
+
+// albumCollector buffers the messages of albums, which arrive
+// as separate updates, until no more of them come in the window.
+type albumCollector struct {
+	window time.Duration
+	mu     sync.Mutex
+	albums map[string]*pendingAlbum
+}
+
+type pendingAlbum struct {
+	u     Update
+	msgs  []Message
+	timer *time.Timer
+	fire  func(Update, []Message)
+}
+
+func newAlbumCollector(window time.Duration) *albumCollector {
+	return &albumCollector{
+		window: window,
+		albums: make(map[string]*pendingAlbum),
+	}
+}
+
+// add buffers the message of the album and (re)starts its timer,
+// after which fire is called with the first update and the messages.
+func (ac *albumCollector) add(u Update, m *Message, fire func(Update, []Message)) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	key := albumKey(m)
+	if a, ok := ac.albums[key]; ok {
+		a.msgs = append(a.msgs, *m)
+		a.timer.Reset(ac.window)
+		return
+	}
+
+	a := &pendingAlbum{u: u, msgs: []Message{*m}, fire: fire}
+	a.timer = time.AfterFunc(ac.window, func() {
+		if ac.take(key, a) {
+			a.dispatch()
+		}
+	})
+	ac.albums[key] = a
+}
+
+// take removes the pending album, reporting
+// whether it wasn't already taken by flush.
+func (ac *albumCollector) take(key string, a *pendingAlbum) bool {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.albums[key] != a {
+		return false
+	}
+	delete(ac.albums, key)
+	return true
+}
+
+// flush dispatches the pending albums without waiting.
+func (ac *albumCollector) flush() {
+	ac.mu.Lock()
+	albums := ac.albums
+	ac.albums = make(map[string]*pendingAlbum)
+	ac.mu.Unlock()
+
+	for _, a := range albums {
+		a.timer.Stop()
+		a.dispatch()
+	}
+}
+
+func (a *pendingAlbum) dispatch() {
+	slices.SortFunc(a.msgs, func(x, y Message) int {
+		return x.ID - y.ID
+	})
+	a.fire(a.u, a.msgs)
+}
+
+func albumKey(m *Message) string {
+	if m.Chat == nil {
+		return m.AlbumID
+	}
+	return litefmt.PSprint(strconv.FormatInt(m.Chat.ID, 10), ":", m.AlbumID)
+}
+
+// handleAlbum buffers the message of the album, if OnAlbum is handled.
+func (b *Bot) handleAlbum(c *Context, m *Message) bool {
+	if _, ok := b.handlers[OnAlbum]; !ok {
+		return false
+	}
+
+	b.albums.add(c.u, m, func(u Update, msgs []Message) {
+		c := b.NewContext(u)
+		c.album = msgs
+		b.handle(OnAlbum, c)
+	})
+	return true
+}
@@ -0,0 +1,61 @@
+package telebot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnAlbum(t *testing.T) {
+	b, err := NewBot(Settings{Offline: true, Synchronous: true, AlbumWindow: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	albums := make(chan []Message, 2)
+	b.Handle(OnAlbum, func(c *Context) error {
+		albums <- c.Album()
+		return nil
+	})
+	b.Handle(OnPhoto, func(c *Context) error {
+		assert.Nil(t, c.Album())
+		albums <- nil
+		return nil
+	})
+
+	chat := &Chat{ID: 1}
+	photo := func(id int, album string) Update {
+		return Update{Message: &Message{ID: id, Chat: chat, AlbumID: album, Photo: &Photo{}}}
+	}
+
+	assert.True(t, b.ProcessUpdate(photo(3, "a")))
+	assert.True(t, b.ProcessUpdate(photo(1, "a")))
+	assert.True(t, b.ProcessUpdate(photo(5, "b")))
+	assert.True(t, b.ProcessUpdate(photo(2, "a")))
+
+	// Photos out of albums aren't delayed.
+	assert.True(t, b.ProcessUpdate(photo(4, "")))
+	assert.Nil(t, <-albums)
+
+	var got [][]int
+	for i := 0; i < 2; i++ {
+		var ids []int
+		for _, m := range <-albums {
+			ids = append(ids, m.ID)
+		}
+		got = append(got, ids)
+	}
+	assert.ElementsMatch(t, [][]int{{1, 2, 3}, {5}}, got)
+
+	// Stopping the bot dispatches the pending albums.
+	b.albums.window = time.Hour
+	b.ProcessUpdate(photo(6, "c"))
+	b.albums.flush()
+	select {
+	case msgs := <-albums:
+		require.Len(t, msgs, 1)
+		assert.Equal(t, 6, msgs[0].ID)
+	default:
+		t.Fatal("album is not flushed")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"mime/multipart"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/3JoB/ulib/litefmt"
@@ -14,6 +15,7 @@ import (
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/3JoB/telebot/v2/pkg/net"
 	"github.com/3JoB/telebot/v2/pkg/updates"
 )
 
@@ -27,11 +29,44 @@ import (
 //
 // It will be automatically released when err != nil, so there is no need to release it
 // again. At the same time, additional judgment is made on the null pointer in the pool.
+//
+// When Settings.AutoMigrate is enabled, the chat_id of a map payload is
+// redirected to the supergroup the chat was migrated to, and the call is
+// retried once if Telegram reports the migration.
 func (b *Bot) Raw(method string, payload ...any) (*bytes.Buffer, error) {
+	return b.rawCtx(nil, method, payload...)
+}
+
+// rawCtx is Raw, which traces the request as a child of the span.
+// Without the span, it's taken from the send options embedded
+// into the map payload, if any.
+func (b *Bot) rawCtx(span Span, method string, payload ...any) (*bytes.Buffer, error) {
+	var p any
+	if len(payload) > 0 {
+		p = payload[0]
+	}
+	if params, ok := p.(map[string]any); ok {
+		if s := takeSpan(params); span == nil {
+			span = s
+		}
+	}
+
+	b.redirect(p)
+	buf, err := b.raw(span, method, payload...)
+	if b.migrate(err, p) {
+		return b.raw(span, method, payload...)
+	}
+	return buf, err
+}
+
+func (b *Bot) raw(span Span, method string, payload ...any) (_ *bytes.Buffer, err error) {
 	url := b.buildUrl(method)
 
 	req, resp := b.client.Acquire()
 	defer b.client.Release(req, resp)
+	if b.observer != nil || b.tracer != nil {
+		defer b.observeRequest(method, resp, b.startRequestSpan(span, method), time.Now(), &err)
+	}
 	buf := pool.NewBuffer()
 	req.SetRequestURI(url)
 	req.MethodPOST()
@@ -58,32 +93,56 @@ func (b *Bot) Raw(method string, payload ...any) (*bytes.Buffer, error) {
 }
 
 func (b *Bot) buildUrl(method string) string {
+	// URLCache is set by NewBot and setServer. Writing it here raced
+	// between the requests sent concurrently, like scheduled jobs.
 	if b.URLCache != "" {
 		return litefmt.PSprint(b.URLCache, method)
 	}
-	b.URLCache = litefmt.PSprint(b.URL, "/bot", b.Token, "/")
-	return litefmt.PSprint(b.URLCache, method)
+	return litefmt.PSprint(b.URL, "/bot", b.Token, "/", method)
 }
 
 func (b *Bot) sendFiles(method string, files map[string]File, params map[string]any) (*bytes.Buffer, error) {
-	rawFiles := make(map[string]any)
+	span := takeSpan(params)
+	b.redirect(params)
+	buf, err := b.sendFilesOnce(span, method, files, params)
+	if !b.migrate(err, params) {
+		return buf, err
+	}
+
+	// Readers are drained by the first attempt, so they can't be resent.
+	for _, f := range files {
+		if f.FileReader != nil && !f.InCloud() && f.FileURL == "" && !f.OnDisk() {
+			return buf, err
+		}
+	}
+	return b.sendFilesOnce(span, method, files, params)
+}
+
+func (b *Bot) sendFilesOnce(span Span, method string, files map[string]File, params map[string]any) (_ *bytes.Buffer, err error) {
+	rawFiles := make(map[string]File)
 	for name, f := range files {
 		switch {
 		case f.InCloud():
 			params[name] = f.FileID
 		case f.FileURL != "":
 			params[name] = f.FileURL
+		case b.uploadsByPath(&f):
+			params[name] = fileURI(f.FileLocal)
 		case f.OnDisk():
-			rawFiles[name] = f.FileLocal
+			f.FileReader = nil
+			rawFiles[name] = f
 		case f.FileReader != nil:
-			rawFiles[name] = f.FileReader
+			rawFiles[name] = f
 		default:
 			return nil, fmt.Errorf("telebot: file for field %s doesn't exist", name)
 		}
+		if b.uploadTooBig(&f) {
+			return nil, ErrFileTooBig
+		}
 	}
 
 	if len(rawFiles) == 0 {
-		return b.Raw(method, params)
+		return b.raw(span, method, params)
 	}
 
 	pipeReader, pipeWriter := io.Pipe()
@@ -93,7 +152,7 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 		defer pipeWriter.Close()
 
 		for field, file := range rawFiles {
-			if err := addFileToWriter(writer, files[field].fileName, field, file); err != nil {
+			if err := addFileToWriter(writer, field, file); err != nil {
 				pipeWriter.CloseWithError(err) //nolint:errcheck
 				return
 			}
@@ -113,8 +172,12 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 	url := b.buildUrl(method)
 	req, resp := b.client.Acquire()
 	defer b.client.Release(req, resp)
+	if b.observer != nil || b.tracer != nil {
+		defer b.observeRequest(method, resp, b.startRequestSpan(span, method), time.Now(), &err)
+	}
 	req.SetRequestURI(url)
 	buf := pool.NewBuffer()
+	req.SetWriter(buf)
 
 	if err := req.WriteFile(writer.FormDataContentType(), pipeReader); err != nil {
 		err = wrapError(err)
@@ -129,7 +192,6 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 		ReleaseBuffer(buf)
 		return nil, err
 	}
-	defer b.client.ReleaseResponse(resp)
 
 	if resp.IsStatusCode(500) {
 		return nil, ErrInternal
@@ -138,20 +200,38 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 	return buf, extractOk(buf)
 }
 
-func addFileToWriter(writer *multipart.Writer, filename, field string, file any) error {
-	var reader io.Reader
-	switch r := file.(type) {
-	case io.Reader:
-		reader = r
-	case string:
-		f, err := os.Open(r)
+func (b *Bot) observeRequest(method string, resp net.NetResponse, span Span, start time.Time, err *error) {
+	status := resp.StatusCode()
+	if b.observer != nil {
+		b.observer.OnRequest(method, status, time.Since(start), *err)
+	}
+	if span != nil {
+		span.SetAttribute(AttrStatus, status)
+		if *err != nil {
+			span.SetError(*err)
+		}
+		span.End()
+	}
+}
+
+func addFileToWriter(writer *multipart.Writer, field string, file File) error {
+	reader := file.FileReader
+	if reader == nil {
+		f, err := os.Open(file.FileLocal)
 		if err != nil {
 			return err
 		}
 		defer f.Close()
 		reader = f
+	}
+
+	filename := file.fileName
+	switch {
+	case filename != "":
+	case file.FileReader == nil:
+		filename = filepath.Base(file.FileLocal)
 	default:
-		return fmt.Errorf("telebot: file for field %v should be io.ReadCloser or string", field)
+		filename = field
 	}
 
 	part, err := writer.CreateFormFile(field, filename)
@@ -159,6 +239,9 @@ func addFileToWriter(writer *multipart.Writer, filename, field string, file any)
 		return err
 	}
 
+	if file.OnProgress != nil {
+		reader = &progressReader{r: reader, total: file.size(reader), fn: file.OnProgress}
+	}
 	_, err = io.Copy(part, reader)
 	return err
 }
@@ -186,16 +269,39 @@ func (b *Bot) sendMedia(media Media, params map[string]any, files map[string]Fil
 		kind = "video_note"
 	}
 
-	sendFiles := map[string]File{kind: *media.MediaFile()}
+	file := *media.MediaFile()
+	sendFiles := map[string]File{kind: file}
 	for k, v := range files {
 		sendFiles[k] = v
 	}
 
-	data, err := b.sendFiles(what, sendFiles, params)
+	key := b.fileCacheKey(media.MediaType(), &file)
+	if id, ok := b.cachedFileID(key); ok {
+		sendFiles[kind] = File{FileID: id}
+		msg, err := b.sendMediaOnce(what, sendFiles, params)
+		if !isFileRejected(err) {
+			return msg, err
+		}
+
+		// The cached file ID is outdated, so the file is uploaded again.
+		delete(params, kind)
+		sendFiles[kind] = file
+	}
+
+	msg, err := b.sendMediaOnce(what, sendFiles, params)
+	if err == nil && key != "" {
+		if m := msg.Media(); m != nil {
+			b.cacheFileID(key, m.MediaFile().FileID)
+		}
+	}
+	return msg, err
+}
+
+func (b *Bot) sendMediaOnce(method string, files map[string]File, params map[string]any) (*Message, error) {
+	data, err := b.sendFiles(method, files, params)
 	if err != nil {
 		return nil, err
 	}
-
 	return extractMessage(data)
 }
 
@@ -241,12 +347,12 @@ type extracts struct {
 	Ok          bool               `json:"ok"`
 	Code        int                `json:"error_code"`
 	Description string             `json:"description"`
-	Parameters  map[string]float64 `json:"parameters"`
+	Parameters  ResponseParameters `json:"parameters"`
 }
 
 // extractOk checks given result for error. If result is ok returns nil.
 // In other cases it extracts API error. If error is not presented
-// in errors.go, it will be prefixed with `unknown` keyword.
+// in errors.go, a new Error with the given code and description is returned.
 func extractOk(data *bytes.Buffer) error {
 	var e extracts
 	if err := defaultJson.Unmarshal(data.Bytes(), &e); err != nil {
@@ -258,35 +364,28 @@ func extractOk(data *bytes.Buffer) error {
 	}
 	ReleaseBuffer(data)
 
-	err := Err(e.Description)
-	switch err {
-	case nil:
-	case ErrGroupMigrated:
-		migratedTo, ok := e.Parameters["migrate_to_chat_id"]
-		if !ok {
-			return NewError(e.Code, e.Description)
-		}
+	return e.err()
+}
 
-		return GroupError{
-			err:        err.(*Error),
-			MigratedTo: int64(migratedTo),
-		}
-	default:
-		return err
+// err builds the error described by the response, wrapping it into
+// GroupError or FloodError if the response has matching parameters.
+func (e *extracts) err() error {
+	err := lookupError(e.Code, e.Description)
+	if err == nil {
+		err = NewError(e.Code, e.Description)
 	}
 
-	if e.Code == 429 {
-		retryAfter, ok := e.Parameters["retry_after"]
-		if !ok {
-			return NewError(e.Code, e.Description)
+	switch {
+	case e.Parameters.MigrateTo != 0:
+		return GroupError{
+			err:        err,
+			MigratedTo: e.Parameters.MigrateTo,
 		}
-
-		err = FloodError{
+	case e.Parameters.RetryAfter != 0:
+		return FloodError{
 			err:        NewError(e.Code, e.Description),
-			RetryAfter: int(retryAfter),
+			RetryAfter: e.Parameters.RetryAfter,
 		}
-	} else {
-		err = fmt.Errorf("telegram: %s (%d)", e.Description, e.Code)
 	}
 
 	return err
@@ -325,6 +424,17 @@ func (b *Bot) verboses(method string, payload any, data *bytes.Buffer) {
 	body = bytes.ReplaceAll(body, unsafeConvert.BytePointer(`"{`), unsafeConvert.BytePointer(`{`))
 	body = bytes.ReplaceAll(body, unsafeConvert.BytePointer(`}"`), unsafeConvert.BytePointer(`}`))
 
+	// Verbose is opted in explicitly, so the requests are logged
+	// at the level the default handlers of the loggers don't drop.
+	if l, ok := b.logger.(LeveledLogger); ok {
+		l.Info("telebot: sent request",
+			Field{FieldMethod, method},
+			Field{"params", unsafeConvert.StringSlice(body)},
+			Field{"response", data.String()},
+		)
+		return
+	}
+
 	b.logger.Printf(
 		"[verbose] telebot: sent request\nMethod: %v\nParams: %v\nResponse: %v",
 		method, indent(body), indent(data.Bytes()),
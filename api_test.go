@@ -1,11 +1,16 @@
 package telebot
 
 import (
+	"bytes"
+	"io"
+	"net/http"
 	"testing"
 
 	"github.com/3JoB/ulib/pool"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/3JoB/telebot/v2/pkg/net"
 )
 
 func TestExtractOk(t *testing.T) {
@@ -64,3 +69,44 @@ func TestExtractMessage(t *testing.T) {
 	_, err = extractMessage(buf)
 	require.NoError(t, err)
 }
+
+func TestSendFilesProgress(t *testing.T) {
+	data := bytes.Repeat([]byte("telebot"), 100_000)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("document")
+		require.NoError(t, err)
+		got, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+		assert.Equal(t, "1", r.FormValue("chat_id"))
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1,"document":{"file_id":"doc"}}}`))
+	}
+
+	for name, client := range map[string]net.NetFrame{
+		"fasthttp": net.NewFastHTTPClient(),
+		"net/http": net.NewHTTPClient(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			b := newFakeBot(t, Settings{Client: client}, handler)
+
+			for _, reader := range []io.Reader{bytes.NewReader(data), io.MultiReader(bytes.NewReader(data))} {
+				var sent, total int64
+				doc := &Document{File: FromReader(reader)}
+				doc.OnProgress = func(s, t int64) {
+					sent, total = s, t
+				}
+
+				msg, err := b.Send(&Chat{ID: 1}, doc)
+				require.NoError(t, err)
+				assert.Equal(t, "doc", msg.Document.FileID)
+				assert.Equal(t, int64(len(data)), sent)
+				if _, ok := reader.(*bytes.Reader); ok {
+					assert.Equal(t, int64(len(data)), total)
+				} else {
+					assert.Equal(t, int64(-1), total)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package telebot
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BindError is returned by Context.Bind if the arguments
+// don't match the struct.
+type BindError struct {
+	// Field is the name of the argument, if any.
+	Field string
+	Err   error
+}
+
+func (e *BindError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+var errMissingArg = errors.New("missing argument")
+
+// Validator is implemented by structs, which check
+// their fields after Context.Bind fills them.
+type Validator interface {
+	Validate() error
+}
+
+// Bind fills the exported fields of the struct pointed by v with the
+// arguments of the update (see Args) by their order. A slice field
+// takes all the remaining arguments.
+//
+// Fields are configured with the "tele" tag: the first option is the
+// name of the argument used in errors, "required" fails on a missing
+// argument, and "-" skips the field:
+//
+//	type BanArgs struct {
+//		User   int64         `tele:"user,required"`
+//		For    time.Duration `tele:"for"`
+//		Reason []string      `tele:"reason"`
+//	}
+//
+// Strings, booleans, integers, floats, time.Duration and
+// encoding.TextUnmarshaler are supported, as well as pointers
+// to them. If the struct implements Validator, it's validated.
+// All the errors are of *BindError type.
+func (c *Context) Bind(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("telebot: bind value must be a pointer to struct")
+	}
+
+	args := c.Args()
+	if len(args) == 1 && args[0] == "" {
+		args = nil
+	}
+
+	rv = rv.Elem()
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("tele")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		if len(args) == 0 {
+			if slices.Contains(strings.Split(opts, ","), "required") {
+				return &BindError{Field: name, Err: errMissingArg}
+			}
+			continue
+		}
+
+		field := rv.Field(i)
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+			field.Set(reflect.MakeSlice(field.Type(), len(args), len(args)))
+			for j, arg := range args {
+				if err := bindArg(field.Index(j), arg); err != nil {
+					return &BindError{Field: name, Err: err}
+				}
+			}
+			args = nil
+			continue
+		}
+
+		if err := bindArg(field, args[0]); err != nil {
+			return &BindError{Field: name, Err: err}
+		}
+		args = args[1:]
+	}
+
+	return validate(v)
+}
+
+// validate validates the value, if it implements Validator.
+func validate(v any) error {
+	if v, ok := v.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return &BindError{Err: err}
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func bindArg(v reflect.Value, arg string) error {
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(arg))
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return errors.New("expected duration, like 1h30m")
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(arg)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(arg)
+		if err != nil {
+			return errors.New("expected true or false")
+		}
+		v.SetBool(b)
+	case v.CanInt():
+		n, err := strconv.ParseInt(arg, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected integer")
+		}
+		v.SetInt(n)
+	case v.CanUint():
+		n, err := strconv.ParseUint(arg, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected non-negative integer")
+		}
+		v.SetUint(n)
+	case v.CanFloat():
+		n, err := strconv.ParseFloat(arg, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected number")
+		}
+		v.SetFloat(n)
+	default:
+		return errors.New("unsupported type " + v.Type().String())
+	}
+	return nil
+}
+
+// HandleCallback registers the handler of the callback endpoint,
+// which receives the callback data decoded to T, see UnmarshalCallback.
+// The data of the buttons is encoded with MarshalCallback, and is
+// kept in Settings.CallbackStore if it's too long. If T implements
+// Validator, it's validated. Decode errors are passed to
+// Settings.OnBindError.
+//
+//	data, err := tele.MarshalCallback(BanArgs{User: id})
+//	...
+//	btn := markup.Data("Ban", "ban", data)
+func HandleCallback[T any](b *Bot, endpoint any, h func(c *Context, data T) error, m ...HandlerFunc) {
+	b.Handle(endpoint, bindHandler(h, bindCallback), m...)
+}
+
+// HandleCommand registers the handler of the command endpoint,
+// which receives the command arguments bound to T, see Context.Bind.
+// Bind errors are passed to Settings.OnBindError.
+func HandleCommand[T any](b *Bot, endpoint any, h func(c *Context, args T) error, m ...HandlerFunc) {
+	b.Handle(endpoint, bindHandler(h, (*Context).Bind), m...)
+}
+
+// bindCallback decodes the callback data to the value pointed by v.
+func bindCallback(c *Context, v any) error {
+	if err := UnmarshalCallback(c.Data(), v); err != nil {
+		return &BindError{Err: err}
+	}
+	return validate(v)
+}
+
+// bindHandler decodes the update to T with the decode function
+// and passes it to the handler.
+func bindHandler[T any](h func(*Context, T) error, decode func(*Context, any) error) HandlerFunc {
+	return func(c *Context) error {
+		var data T
+		if err := decode(c, &data); err != nil {
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) {
+				return err
+			}
+			return c.b.onBindError(c, err)
+		}
+		return h(c, data)
+	}
+}
+
+// defaultBindError replies with the error to the message,
+// or shows it as an alert to the callback.
+func defaultBindError(c *Context, err error) error {
+	switch {
+	case c.Callback() != nil:
+		return c.Respond(&CallbackResponse{Text: err.Error(), ShowAlert: true})
+	case c.Message() != nil:
+		_, err := c.Reply(err.Error())
+		return err
+	default:
+		return err
+	}
+}
@@ -0,0 +1,129 @@
+package telebot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type banArgs struct {
+	User   int64         `tele:"user,required"`
+	For    time.Duration `tele:"for"`
+	Silent *bool
+	Reason []string `tele:"reason"`
+	Skip   string   `tele:"-"`
+}
+
+func (a banArgs) Validate() error {
+	if a.User <= 0 {
+		return errors.New("user must be positive")
+	}
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	msg := func(payload string) *Context {
+		return &Context{u: Update{Message: &Message{Payload: payload}}}
+	}
+
+	var args banArgs
+	require.NoError(t, msg("42 1h true spam and flood").Bind(&args))
+	assert.Equal(t, int64(42), args.User)
+	assert.Equal(t, time.Hour, args.For)
+	require.NotNil(t, args.Silent)
+	assert.True(t, *args.Silent)
+	assert.Equal(t, []string{"spam", "and", "flood"}, args.Reason)
+
+	args = banArgs{}
+	require.NoError(t, msg("7").Bind(&args))
+	assert.Equal(t, banArgs{User: 7}, args)
+
+	var bindErr *BindError
+	err := msg("").Bind(&args)
+	require.ErrorAs(t, err, &bindErr)
+	assert.Equal(t, "user", bindErr.Field)
+	assert.EqualError(t, err, "user: missing argument")
+
+	assert.EqualError(t, msg("x").Bind(&args), "user: expected integer")
+	assert.EqualError(t, msg("1 soon").Bind(&args), "for: expected duration, like 1h30m")
+	assert.EqualError(t, msg("-1").Bind(&args), "user must be positive")
+
+	c := &Context{u: Update{Callback: &Callback{Data: "5|1.5"}}}
+	var data struct {
+		ID    uint
+		Price float32
+	}
+	require.NoError(t, c.Bind(&data))
+	assert.Equal(t, uint(5), data.ID)
+	assert.Equal(t, float32(1.5), data.Price)
+
+	assert.Error(t, c.Bind(data))
+
+	// The options are matched exactly.
+	var opt struct {
+		Name string `tele:"name,notrequired"`
+	}
+	assert.NoError(t, msg("").Bind(&opt))
+}
+
+func TestHandleCallback(t *testing.T) {
+	var bindErr error
+	b, err := NewBot(Settings{
+		Offline:     true,
+		Synchronous: true,
+		OnBindError: func(c *Context, err error) error {
+			bindErr = err
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	var got banArgs
+	HandleCallback(b, &Btn{Unique: "ban"}, func(c *Context, args banArgs) error {
+		got = args
+		return nil
+	})
+
+	data, err := MarshalCallback(banArgs{User: 42, For: 10 * time.Minute, Reason: []string{"spam"}})
+	require.NoError(t, err)
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fban|" + data}})
+	assert.Equal(t, banArgs{User: 42, For: 10 * time.Minute, Reason: []string{"spam"}}, got)
+	assert.NoError(t, bindErr)
+
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fban|" + data[:2]}})
+	assert.ErrorIs(t, bindErr, errBadCallbackData)
+
+	data, err = MarshalCallback(banArgs{User: -1})
+	require.NoError(t, err)
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\fban|" + data}})
+	assert.EqualError(t, bindErr, "user must be positive")
+}
+
+func TestHandleCommand(t *testing.T) {
+	var bindErr error
+	b, err := NewBot(Settings{
+		Offline:     true,
+		Synchronous: true,
+		OnBindError: func(c *Context, err error) error {
+			bindErr = err
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	var got banArgs
+	HandleCommand(b, "/ban", func(c *Context, args banArgs) error {
+		got = args
+		return nil
+	})
+
+	b.ProcessUpdate(Update{Message: &Message{Text: "/ban 42 10m"}})
+	assert.Equal(t, banArgs{User: 42, For: 10 * time.Minute}, got)
+	assert.NoError(t, bindErr)
+
+	b.ProcessUpdate(Update{Message: &Message{Text: "/ban x"}})
+	assert.EqualError(t, bindErr, "user: expected integer")
+}
@@ -1,17 +1,20 @@
 package telebot
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/3JoB/ulib/litefmt"
-	"github.com/3JoB/ulib/pool"
 	"github.com/3JoB/unsafeConvert"
+	"github.com/cornelk/hashmap"
 
 	"github.com/3JoB/telebot/v2/pkg/json"
 	"github.com/3JoB/telebot/v2/pkg/json/sonnet"
@@ -53,24 +56,46 @@ func NewBot(pref Settings) (*Bot, error) {
 		logger = NewZeroLogger()
 	}
 
+	onBindError := pref.OnBindError
+	if onBindError == nil {
+		onBindError = defaultBindError
+	}
+
 	if pref.URL == "" {
 		pref.URL = DefaultApiURL
 	}
 	if pref.Poller == nil {
 		pref.Poller = &LongPoller{}
 	}
+	if pref.AlbumWindow == 0 {
+		pref.AlbumWindow = 500 * time.Millisecond
+	}
 
 	bot := &Bot{
-		Token:  pref.Token,
-		URL:    pref.URL,
-		Poller: pref.Poller,
+		Token:    pref.Token,
+		URL:      pref.URL,
+		URLCache: litefmt.PSprint(pref.URL, "/bot", pref.Token, "/"),
+		Poller:   pref.Poller,
 
 		Updates:  make(chan Update, pref.Updates),
 		handlers: make(map[string]*Handle),
 		stop:     make(chan chan struct{}),
 
+		migrations:  hashmap.New[int64, int64](),
+		autoMigrate: pref.AutoMigrate,
+
+		callbackStore:    pref.CallbackStore,
+		callbackSecret:   pref.CallbackSecret,
+		callbackRejected: pref.CallbackRejected,
+		onBindError:      onBindError,
+		fileCache:        pref.FileCache,
+		albums:           newAlbumCollector(pref.AlbumWindow),
+
+		observer:    pref.Observer,
+		tracer:      pref.Tracer,
 		synchronous: pref.Synchronous,
 		verbose:     pref.Verbose,
+		local:       pref.Local,
 		parseMode:   pref.ParseMode,
 		client:      client,
 		json:        pref_json,
@@ -88,6 +113,7 @@ func NewBot(pref Settings) (*Bot, error) {
 	}
 
 	bot.group = bot.Group()
+	bot.scheduler = newScheduler(bot, pref.JobStore)
 	return bot, nil
 }
 
@@ -104,7 +130,22 @@ type Bot struct {
 	group       *Group
 	json        json.Json
 	logger      Logger
+	observer    Observer
+	tracer      Tracer
 	handlers    map[string]*Handle
+	commands    []*registeredCommand
+	synced      []commandKey
+	migrations  *hashmap.Map[int64, int64]
+	autoMigrate bool
+
+	callbackStore    CallbackStore
+	callbackSecret   []byte
+	callbackRejected *CallbackResponse
+	onBindError      func(*Context, error) error
+	fileCache        FileCache
+	albums           *albumCollector
+	scheduler        *scheduler
+
 	synchronous bool
 	verbose     bool
 	local       bool
@@ -133,8 +174,21 @@ type Settings struct {
 	// Use for debugging purposes only.
 	Verbose bool
 
+	// JobStore keeps the jobs scheduled with Schedule and other
+	// methods, defaulted to the memory store. Use NewJobDiskStore
+	// or your own store to keep the jobs across restarts.
+	JobStore JobStore
+
+	// AlbumWindow is the time OnAlbum waits for more messages of the
+	// album after the last one, defaulted to 500 milliseconds.
+	AlbumWindow time.Duration
+
 	// Local flags the bot, it's on the same machine as telegram-bot-api,
 	// learn more about it: https://github.com/tdlib/telegram-bot-api
+	//
+	// Files on disk are sent to the local server by their paths instead
+	// of being uploaded, and downloaded files are read from its disk.
+	// Use MoveToLocal to move the bot from the cloud server.
 	Local bool
 
 	// The Json interface is used to customize the json handle.
@@ -160,6 +214,45 @@ type Settings struct {
 
 	// Offline allows to create a bot without network for testing purposes.
 	Offline bool
+
+	// Observer is notified about handled updates and Bot API requests,
+	// see middleware/metrics for the ready-to-use implementation.
+	Observer Observer
+
+	// Tracer starts spans for processed updates and Bot API requests.
+	// See Tracer for the details.
+	Tracer Tracer
+
+	// AutoMigrate makes the bot follow groups upgraded to supergroups.
+	// Requests failed with GroupError are retried on the new chat ID,
+	// the OnMigration handler is called, and later requests to the old
+	// chat ID are redirected. See Bot.Migrate to restore known migrations.
+	AutoMigrate bool
+
+	// CallbackStore keeps the callback data longer than MaxCallbackData
+	// bytes, which would be rejected by Telegram otherwise.
+	// See NewCallbackMemoryStore for the in-memory implementation.
+	CallbackStore CallbackStore
+
+	// CallbackSecret is the key signing the callback data of
+	// signed buttons, see Btn.Signed. Keep it secret and stable
+	// across restarts, so the sent buttons remain valid.
+	CallbackSecret []byte
+
+	// CallbackRejected is the response to callbacks with forged or
+	// expired signed data, or the data expired in the CallbackStore.
+	// By default, an alert is shown.
+	CallbackRejected *CallbackResponse
+
+	// OnBindError handles the arguments, which can't be bound by the
+	// handlers of HandleCallback and HandleCommand. By default, the
+	// error is replied to the message or shown as a callback alert.
+	OnBindError func(c *Context, err error) error
+
+	// FileCache remembers the file IDs of sent media, so the same
+	// files aren't uploaded again. See NewFileMemoryCache and
+	// NewFileDiskCache for the ready-to-use implementations.
+	FileCache FileCache
 }
 
 func (b *Bot) Logger() Logger {
@@ -222,16 +315,28 @@ func (b *Bot) Handle(endpoint any, h HandlerFunc, m ...HandlerFunc) {
 
 	switch end := endpoint.(type) {
 	case string:
+		handler.endpoint = end
 		b.handlers[end] = handler
 	case CallbackEndpoint:
-		b.handlers[end.CallbackUnique()] = handler
+		handler.endpoint = end.CallbackUnique()
+		switch btn := end.(type) {
+		case *Btn:
+			handler.signed = btn.Sign
+		case *InlineButton:
+			handler.signed = btn.Sign
+		}
+		if handler.signed && len(b.callbackSecret) == 0 {
+			b.logger.Panicf("telebot: signed buttons require Settings.CallbackSecret")
+		}
+		b.handlers[handler.endpoint] = handler
 	default:
 		b.logger.Panicf("telebot: unsupported endpoint")
 	}
 }
 
 // Start brings bot into motion by consuming incoming
-// updates (see Bot.Updates channel).
+// updates (see Bot.Updates channel). The commands
+// registered with Bot.Command are published first.
 func (b *Bot) Start() {
 	if b.Poller == nil {
 		b.logger.Panicf("telebot: can't start without a poller")
@@ -243,6 +348,13 @@ func (b *Bot) Start() {
 	}
 	b.stopClient = make(chan struct{})
 
+	if len(b.commands) > 0 {
+		if err := b.SyncCommands(); err != nil {
+			b.OnError(err, nil)
+		}
+	}
+	b.scheduler.start()
+
 	stop := make(chan struct{})
 	stopConfirm := make(chan struct{})
 
@@ -260,6 +372,8 @@ func (b *Bot) Start() {
 		case confirm := <-b.stop:
 			close(stop)
 			<-stopConfirm
+			b.albums.flush()
+			b.scheduler.stop()
 			close(confirm)
 			b.stopClient = nil
 			return
@@ -406,6 +520,14 @@ func (b *Bot) Send(to Recipient, what any, opts ...any) (*Message, error) {
 
 	sendOpts := extractOptions(opts)
 
+	if sendOpts.Split && splittable(what) {
+		msgs, err := b.sendSplit(to, what, sendOpts)
+		if len(msgs) == 0 {
+			return nil, err
+		}
+		return &msgs[len(msgs)-1], err
+	}
+
 	switch object := what.(type) {
 	case string:
 		return b.sendText(to, object, sendOpts)
@@ -424,8 +546,29 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...any) ([]Message, error) {
 	}
 
 	sendOpts := extractOptions(opts)
+	offsets, seekable := readerOffsets(a)
+	msgs, cached, err := b.sendAlbum(to, a, sendOpts, true)
+	if !cached || !seekable || !isFileRejected(err) {
+		return msgs, err
+	}
+
+	// Some of the cached file IDs are outdated, so the files are uploaded
+	// again, with the readers drained by the first attempt seeked back.
+	for i, offset := range offsets {
+		if _, err := a[i].MediaFile().FileReader.(io.Seeker).Seek(offset, io.SeekStart); err != nil {
+			return nil, wrapError(err)
+		}
+	}
+	msgs, _, err = b.sendAlbum(to, a, sendOpts, false)
+	return msgs, err
+}
+
+// sendAlbum sends the album, using the cached file IDs if cache is set,
+// and reports whether any of them were used.
+func (b *Bot) sendAlbum(to Recipient, a Album, sendOpts *SendOptions, cache bool) (_ []Message, cached bool, _ error) {
 	media := make([]string, len(a))
 	files := make(map[string]File)
+	keys := make(map[int]string)
 
 	for i, x := range a {
 		var (
@@ -434,16 +577,26 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...any) ([]Message, error) {
 			file = x.MediaFile()
 		)
 
+		key := b.fileCacheKey(x.MediaType(), file)
+		id, ok := b.cachedFileID(key)
+		if key != "" {
+			keys[i] = key
+		}
+
 		switch {
+		case ok && cache:
+			repr, cached = id, true
 		case file.InCloud():
 			repr = file.FileID
 		case file.FileURL != "":
 			repr = file.FileURL
+		case b.uploadsByPath(file):
+			repr = fileURI(file.FileLocal)
 		case file.OnDisk() || file.FileReader != nil:
-			repr = litefmt.PSprintP("attach://", unsafeConvert.IntToString(i))
+			repr = litefmt.PSprint("attach://", unsafeConvert.IntToString(i))
 			files[unsafeConvert.IntToString(i)] = *file
 		default:
-			return nil, fmt.Errorf("telebot: album entry #%d does not exist", i)
+			return nil, false, fmt.Errorf("telebot: album entry #%d does not exist", i)
 		}
 
 		im := x.InputMedia()
@@ -461,19 +614,19 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...any) ([]Message, error) {
 
 	params := map[string]any{
 		"chat_id": to.Recipient(),
-		"media":   litefmt.PSprintP("[", strings.Join(media, ","), "]"),
+		"media":   litefmt.PSprint("[", strings.Join(media, ","), "]"),
 	}
 	b.embedSendOptions(params, sendOpts)
 
 	data, err := b.sendFiles("sendMediaGroup", files, params)
 	if err != nil {
-		return nil, err
+		return nil, cached, err
 	}
 	defer ReleaseBuffer(data)
 
 	var resp Response[[]Message]
 	if err := b.json.NewDecoder(data).Decode(&resp); err != nil {
-		return nil, wrapError(err)
+		return nil, cached, wrapError(err)
 	}
 
 	for attachName := range files {
@@ -493,9 +646,10 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...any) ([]Message, error) {
 		}
 
 		a[i].MediaFile().FileID = newID
+		b.cacheFileID(keys[i], newID)
 	}
 
-	return resp.Result, nil
+	return resp.Result, cached, nil
 }
 
 // Reply behaves just like Send() with an exception of "reply-to" indicator.
@@ -649,7 +803,7 @@ func (b *Bot) EditReplyMarkup(msg Editable, markup *ReplyMarkup) (*Message, erro
 		markup = &ReplyMarkup{}
 	}
 
-	processButtons(markup.InlineKeyboard)
+	b.processButtons(markup.InlineKeyboard)
 	data, _ := b.json.Marshal(markup)
 	params["reply_markup"] = unsafeConvert.StringPointer(data)
 
@@ -716,6 +870,8 @@ func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...any) (*Message,
 		repr = file.FileID
 	case file.FileURL != "":
 		repr = file.FileURL
+	case b.uploadsByPath(file):
+		repr = fileURI(file.FileLocal)
 	case file.OnDisk() || file.FileReader != nil:
 		s := file.FileLocal
 		if file.FileReader != nil {
@@ -724,7 +880,7 @@ func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...any) (*Message,
 			thumbName = "thumbnail2"
 		}
 
-		repr = litefmt.PSprintP("attach://", s)
+		repr = litefmt.PSprint("attach://", s)
 		files[s] = *file
 	default:
 		return nil, errors.New("telebot: cannot edit media, it does not exist")
@@ -756,8 +912,10 @@ func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...any) (*Message,
 		im.ParseMode = sendOpts.ParseMode
 	}
 
-	if thumb != nil {
-		im.Thumbnail = litefmt.PSprintP("attach://%v", thumbName)
+	if thumb != nil && b.uploadsByPath(thumb.MediaFile()) {
+		im.Thumbnail = fileURI(thumb.FileLocal)
+	} else if thumb != nil {
+		im.Thumbnail = litefmt.PSprint("attach://", thumbName)
 		files[thumbName] = *thumb.MediaFile()
 	}
 
@@ -791,6 +949,10 @@ func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...any) (*Message,
 //   - If the bot has can_delete_messages permission in a supergroup or a
 //     channel, it can delete any message there.
 func (b *Bot) Delete(msg Editable) error {
+	return b.delete(nil, msg)
+}
+
+func (b *Bot) delete(span Span, msg Editable) error {
 	msgID, chatID := msg.MessageSig()
 
 	params := map[string]any{
@@ -798,7 +960,7 @@ func (b *Bot) Delete(msg Editable) error {
 		"message_id": msgID,
 	}
 
-	r, err := b.Raw("deleteMessage", params)
+	r, err := b.rawCtx(span, "deleteMessage", params)
 	ReleaseBuffer(r)
 	return err
 }
@@ -813,6 +975,10 @@ func (b *Bot) Delete(msg Editable) error {
 // Currently, Telegram supports only a narrow range of possible
 // actions, these are aligned as constants of this package.
 func (b *Bot) Notify(to Recipient, action ChatAction, threadID ...int) error {
+	return b.notify(nil, to, action, threadID...)
+}
+
+func (b *Bot) notify(span Span, to Recipient, action ChatAction, threadID ...int) error {
 	if to == nil {
 		return ErrBadRecipient
 	}
@@ -825,10 +991,52 @@ func (b *Bot) Notify(to Recipient, action ChatAction, threadID ...int) error {
 		params["message_thread_id"] = threadID[0]
 	}
 
-	_, err := b.Raw("sendChatAction", params)
+	_, err := b.rawCtx(span, "sendChatAction", params)
 	return err
 }
 
+// notifyInterval is the interval of resending the chat
+// action, which is shorter than its lifetime of 5 seconds.
+var notifyInterval = 4 * time.Second
+
+// KeepNotifying sends the chat action and keeps resending it in the
+// background, so it doesn't expire, until the context is canceled.
+// It returns the error of the first action, the errors of the next
+// ones are passed to OnError.
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	b.KeepNotifying(ctx, chat, tele.UploadingVideo)
+func (b *Bot) KeepNotifying(ctx context.Context, to Recipient, action ChatAction, threadID ...int) error {
+	return b.keepNotifying(ctx, nil, to, action, threadID...)
+}
+
+func (b *Bot) keepNotifying(ctx context.Context, span Span, to Recipient, action ChatAction, threadID ...int) error {
+	if err := b.notify(span, to, action, threadID...); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(notifyInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err := b.notify(span, to, action, threadID...); err != nil {
+				b.OnError(err, nil)
+			}
+		}
+	}()
+	return nil
+}
+
 // Ship replies to the shipping query, if you sent an invoice
 // requesting an address and the parameter is_flexible was specified.
 //
@@ -838,6 +1046,10 @@ func (b *Bot) Notify(to Recipient, action ChatAction, threadID ...int) error {
 //	b.Ship(query, opts...) // OK with options
 //	b.Ship(query, "Oops!") // Error message
 func (b *Bot) Ship(query *ShippingQuery, what ...any) error {
+	return b.ship(nil, query, what...)
+}
+
+func (b *Bot) ship(span Span, query *ShippingQuery, what ...any) error {
 	params := map[string]any{
 		"shipping_query_id": query.ID,
 	}
@@ -862,12 +1074,16 @@ func (b *Bot) Ship(query *ShippingQuery, what ...any) error {
 		params["shipping_options"] = unsafeConvert.StringPointer(data)
 	}
 
-	_, err := b.Raw("answerShippingQuery", params)
+	_, err := b.rawCtx(span, "answerShippingQuery", params)
 	return err
 }
 
 // Accept finalizes the deal.
 func (b *Bot) Accept(query *PreCheckoutQuery, errorMessage ...string) error {
+	return b.accept(nil, query, errorMessage...)
+}
+
+func (b *Bot) accept(span Span, query *PreCheckoutQuery, errorMessage ...string) error {
 	params := map[string]any{
 		"pre_checkout_query_id": query.ID,
 	}
@@ -879,7 +1095,7 @@ func (b *Bot) Accept(query *PreCheckoutQuery, errorMessage ...string) error {
 		params["error_message"] = errorMessage[0]
 	}
 
-	_, err := b.Raw("answerPreCheckoutQuery", params)
+	_, err := b.rawCtx(span, "answerPreCheckoutQuery", params)
 	return err
 }
 
@@ -892,6 +1108,10 @@ func (b *Bot) Accept(query *PreCheckoutQuery, errorMessage ...string) error {
 //	b.Respond(c)
 //	b.Respond(c, response)
 func (b *Bot) Respond(c *Callback, resp ...*CallbackResponse) error {
+	return b.respond(nil, c, resp...)
+}
+
+func (b *Bot) respond(span Span, c *Callback, resp ...*CallbackResponse) error {
 	var r *CallbackResponse
 	if resp == nil {
 		r = &CallbackResponse{}
@@ -900,7 +1120,7 @@ func (b *Bot) Respond(c *Callback, resp ...*CallbackResponse) error {
 	}
 
 	r.CallbackID = c.ID
-	d, err := b.Raw("answerCallbackQuery", r)
+	d, err := b.rawCtx(span, "answerCallbackQuery", r)
 	ReleaseBuffer(d)
 	return err
 }
@@ -909,13 +1129,17 @@ func (b *Bot) Respond(c *Callback, resp ...*CallbackResponse) error {
 // be responded to once, subsequent attempts to respond to the same query
 // will result in an error.
 func (b *Bot) Answer(query *Query, resp *QueryResponse) error {
+	return b.answer(nil, query, resp)
+}
+
+func (b *Bot) answer(span Span, query *Query, resp *QueryResponse) error {
 	resp.QueryID = query.ID
 
 	for _, result := range resp.Results {
 		result.Process(b)
 	}
 
-	_, err := b.Raw("answerInlineQuery", resp)
+	_, err := b.rawCtx(span, "answerInlineQuery", resp)
 	return err
 }
 
@@ -967,8 +1191,12 @@ func (b *Bot) FileByID(fileID string) (File, error) {
 }
 
 // Download saves the file from Telegram servers locally.
-// Maximum file size to download is 20 MB.
-// To increase the limit up to 2 GB use local Telegram Bot API.
+// Maximum file size to download is 20 MB, the local
+// Telegram Bot API server doesn't limit it (see DownloadLimit).
+//
+// The file is written to a temporary file in the same directory
+// first, which is renamed on success, so a failed download
+// never leaves a partial file under localFilename.
 func (b *Bot) Download(file *File, localFilename string) error {
 	reader, err := b.File(file)
 	if err != nil {
@@ -976,13 +1204,28 @@ func (b *Bot) Download(file *File, localFilename string) error {
 	}
 	defer reader.Close()
 
-	out, err := os.OpenFile(localFilename, os.O_RDWR|os.O_CREATE, 0666)
+	dir, base := filepath.Split(localFilename)
+	if dir == "" {
+		dir = "."
+	}
+	out, err := os.CreateTemp(dir, litefmt.PSprint(".", base, ".*"))
 	if err != nil {
 		return wrapError(err)
 	}
-	defer out.Close()
+	defer os.Remove(out.Name()) //nolint:errcheck // fails after the rename
 
 	if _, err := net.Copy(out, reader); err != nil {
+		out.Close()
+		return wrapError(err)
+	}
+	if err := out.Chmod(0o644); err != nil {
+		out.Close()
+		return wrapError(err)
+	}
+	if err := out.Close(); err != nil {
+		return wrapError(err)
+	}
+	if err := os.Rename(out.Name(), localFilename); err != nil {
 		return wrapError(err)
 	}
 
@@ -994,52 +1237,95 @@ func (b *Bot) buildFileUrl(filepath string) string {
 	return fmt.Sprintf("%v/file/bot%v/%v", b.URL, b.Token, filepath)
 }
 
-// File gets a file from Telegram servers.
-func (b *Bot) File(file *File) (io.ReadWriteCloser, error) {
-	var err error
+// File gets a file from Telegram servers. The returned reader
+// streams the file from the connection and must be closed.
+func (b *Bot) File(file *File) (io.ReadCloser, error) {
+	return b.FileRange(file, 0, 0)
+}
 
-	if b.local {
-		localPath := file.FilePath
-		if file.FilePath == "" {
-			f, err := b.FileByID(file.FileID)
-			if err != nil {
-				return nil, err
-			}
-			// FilePath is updated, allowing user to delete the file from the local server's cache
-			localPath = f.FilePath
-			file.FilePath = localPath
+// FileRange gets the part of the file from Telegram servers starting at
+// the offset, of the length or up to the end if it's not positive. It
+// allows to resume interrupted downloads. If the server ignores the
+// range, the part is cut from the whole file.
+func (b *Bot) FileRange(file *File, offset, length int64) (io.ReadCloser, error) {
+	if limit := b.DownloadLimit(); limit > 0 && file.FileSize > limit {
+		return nil, ErrFileTooBig
+	}
+
+	// The local server returns absolute paths of the files on its disk,
+	// which are read directly. The cloud file paths expire, so they are
+	// requested every time.
+	path := file.FilePath
+	if !b.local || path == "" {
+		f, err := b.FileByID(file.FileID)
+		if err != nil {
+			return nil, err
 		}
-
-		return os.Open(localPath)
+		// FilePath is updated, allowing user to delete the file from the local server's cache
+		path = f.FilePath
+		file.FilePath = path
 	}
-
-	f, err := b.FileByID(file.FileID)
-	if err != nil {
-		return nil, err
+	if b.local && filepath.IsAbs(path) {
+		return openRange(path, offset, length)
 	}
 
-	url := b.buildFileUrl(f.FilePath)
-	file.FilePath = f.FilePath // saving file path
+	url := b.buildFileUrl(path)
 
 	req, resp := b.client.Acquire()
 	defer b.client.Release(req, resp)
 
-	w := pool.NewBufferClose()
-	req.SetWriteCloser(w)
 	req.MethodGET()
 	req.SetRequestURI(url)
+	if offset > 0 || length > 0 {
+		end := ""
+		if length > 0 {
+			end = strconv.FormatInt(offset+length-1, 10)
+		}
+		req.SetHeader("Range", litefmt.PSprint("bytes=", strconv.FormatInt(offset, 10), "-", end))
+	}
 
-	if err := req.Do(); err != nil {
-		b.client.ReleaseRequest(req)
+	body, err := req.DoStream()
+	if err != nil {
 		return nil, wrapError(err)
 	}
-	b.client.ReleaseRequest(req)
 
-	defer b.client.ReleaseResponse(resp)
-	if !resp.IsStatusCode(200) {
+	switch {
+	case resp.IsStatusCode(206):
+	case resp.IsStatusCode(200):
+		if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+			body.Close()
+			return nil, wrapError(err)
+		}
+		if length > 0 {
+			return readCloser{io.LimitReader(body, length), body}, nil
+		}
+	default:
+		body.Close()
 		return nil, fmt.Errorf("telebot: expected status 200 but got %v", resp.StatusCode())
 	}
-	return w, nil
+	return body, nil
+}
+
+// openRange opens the part of the file on disk.
+func openRange(path string, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, wrapError(err)
+	}
+	if length > 0 {
+		return readCloser{io.LimitReader(f, length), f}, nil
+	}
+	return f, nil
+}
+
+// readCloser closes the underlying reader of the limited one.
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
 // StopLiveLocation stops broadcasting live message location
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
@@ -35,6 +36,24 @@ func newTestBot() (*Bot, error) {
 	return NewBot(defaultSettings())
 }
 
+// newFakeBot starts the fake Bot API server with the handler and returns
+// the offline bot with the settings, which sends requests to the server.
+// The server is closed when the test finishes.
+func newFakeBot(t *testing.T, pref Settings, h http.HandlerFunc) *Bot {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	pref.URL, pref.Offline = srv.URL, true
+	b, err := NewBot(pref)
+	require.NoError(t, err)
+	return b
+}
+
+// fakeMethod returns the Bot API method of the request to the fake server.
+func fakeMethod(r *http.Request) string {
+	return r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+}
+
 func TestNewBot(t *testing.T) {
 	var pref Settings
 	_, err := NewBot(pref)
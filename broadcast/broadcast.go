@@ -0,0 +1,234 @@
+// Package broadcast sends a message to many recipients.
+//
+// The messages are sent at a steady rate below the flood limits, and
+// flood errors are waited out. Recipients, which blocked the bot or
+// deleted their accounts, are reported, so they can be removed from the
+// audience. The progress is checkpointed, so an interrupted broadcast
+// resumes from where it stopped after a restart.
+//
+//	bc := broadcast.New(b, "news-42", "Hello!")
+//	bc.Checkpoint, _ = broadcast.NewFileCheckpoint("broadcasts.json")
+//	bc.OnUnreachable = func(r tele.Recipient, err error) { ... }
+//	stats, err := bc.Run(ctx, broadcast.IDs(users))
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+// DefaultRate is the default number of messages sent per second.
+const DefaultRate = 25
+
+// DefaultCheckpointEvery is the default number of recipients
+// processed between the checkpoints: the progress is saved
+// after every message.
+const DefaultCheckpointEvery = 1
+
+// API is the part of the bot API used to broadcast
+// messages, which *tele.Bot implements.
+type API interface {
+	Send(to tele.Recipient, what any, opts ...any) (*tele.Message, error)
+	Copy(to tele.Recipient, msg tele.Editable, opts ...any) (*tele.Message, error)
+}
+
+// Stats are the counters of the broadcast.
+type Stats struct {
+	// Sent is the number of recipients, which received the message.
+	Sent int `json:"sent"`
+
+	// Unreachable is the number of recipients, which blocked
+	// the bot or deleted their accounts.
+	Unreachable int `json:"unreachable"`
+
+	// Failed is the number of recipients, which didn't
+	// receive the message because of other errors.
+	Failed int `json:"failed"`
+
+	// Flooded is the number of times the flood limit was hit.
+	Flooded int `json:"flooded"`
+}
+
+// Processed returns the number of recipients processed so far.
+func (s Stats) Processed() int {
+	return s.Sent + s.Unreachable + s.Failed
+}
+
+// Broadcast sends the same message to the recipients.
+type Broadcast struct {
+	// ID identifies the broadcast in the checkpoint.
+	ID string
+
+	// Rate is the number of messages sent per second,
+	// defaulted to DefaultRate.
+	Rate int
+
+	// Checkpoint keeps the progress of the broadcast. Without
+	// it, the broadcast always starts from the first recipient.
+	Checkpoint Checkpoint
+
+	// CheckpointEvery is the number of recipients processed between
+	// the checkpoints, defaulted to DefaultCheckpointEvery. Larger
+	// values write the checkpoint less often, but the broadcast
+	// resumed after a crash resends up to CheckpointEvery-1 messages.
+	CheckpointEvery int
+
+	// OnUnreachable is called for the recipients, which blocked the
+	// bot (tele.ErrBlockedByUser) or deleted their accounts
+	// (tele.ErrUserIsDeactivated).
+	OnUnreachable func(to tele.Recipient, err error)
+
+	// OnError is called for the recipients, which
+	// didn't receive the message because of other errors.
+	OnError func(to tele.Recipient, err error)
+
+	api  API
+	what any
+	from tele.Editable
+	opts []any
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New returns the broadcast sending what, which is anything Bot.Send accepts.
+func New(api API, id string, what any, opts ...any) *Broadcast {
+	return &Broadcast{ID: id, api: api, what: what, opts: opts}
+}
+
+// NewCopy returns the broadcast copying the message.
+func NewCopy(api API, id string, msg tele.Editable, opts ...any) *Broadcast {
+	return &Broadcast{ID: id, api: api, from: msg, opts: opts}
+}
+
+// Stats returns the current stats of the broadcast,
+// it's safe to call while the broadcast runs.
+func (bc *Broadcast) Stats() Stats {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.stats
+}
+
+// Run sends the message to the recipients, which must come in the same
+// order every time for the broadcast to resume correctly. It returns
+// when all of them are processed or the context is canceled, saving
+// the last checkpoint in both cases. A finished broadcast isn't run again.
+func (bc *Broadcast) Run(ctx context.Context, rs Recipients) (Stats, error) {
+	var p Progress
+	if bc.Checkpoint != nil {
+		var err error
+		if p, err = bc.Checkpoint.Load(bc.ID); err != nil {
+			return Stats{}, err
+		}
+	}
+
+	bc.mu.Lock()
+	bc.stats = p.Stats
+	bc.mu.Unlock()
+	if p.Done {
+		return p.Stats, nil
+	}
+	if err := skip(rs, p.Stats.Processed()); err != nil {
+		return p.Stats, err
+	}
+
+	rate := bc.Rate
+	if rate <= 0 {
+		rate = DefaultRate
+	}
+	every := bc.CheckpointEvery
+	if every <= 0 {
+		every = DefaultCheckpointEvery
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	for n := 1; ; n++ {
+		to, err := rs.Next()
+		if err != nil {
+			return bc.save(false, err)
+		}
+		if to == nil {
+			return bc.save(true, nil)
+		}
+
+		if err := bc.send(ctx, ticker.C, to); err != nil {
+			return bc.save(false, err)
+		}
+		if n%every == 0 {
+			if _, err := bc.save(false, nil); err != nil {
+				return bc.Stats(), err
+			}
+		}
+	}
+}
+
+// send sends the message to the recipient, waiting for the
+// next tick and retrying after flood errors.
+func (bc *Broadcast) send(ctx context.Context, tick <-chan time.Time, to tele.Recipient) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+		}
+
+		var err error
+		if bc.from != nil {
+			_, err = bc.api.Copy(to, bc.from, bc.opts...)
+		} else {
+			_, err = bc.api.Send(to, bc.what, bc.opts...)
+		}
+
+		var flood tele.FloodError
+		if errors.As(err, &flood) {
+			bc.count(func(s *Stats) { s.Flooded++ })
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(flood.RetryAfter) * time.Second):
+			}
+			continue
+		}
+
+		switch {
+		case err == nil:
+			bc.count(func(s *Stats) { s.Sent++ })
+		case errors.Is(err, tele.ErrBlockedByUser), errors.Is(err, tele.ErrUserIsDeactivated):
+			bc.count(func(s *Stats) { s.Unreachable++ })
+			if bc.OnUnreachable != nil {
+				bc.OnUnreachable(to, err)
+			}
+		default:
+			bc.count(func(s *Stats) { s.Failed++ })
+			if bc.OnError != nil {
+				bc.OnError(to, err)
+			}
+		}
+		return nil
+	}
+}
+
+func (bc *Broadcast) count(f func(*Stats)) {
+	bc.mu.Lock()
+	f(&bc.stats)
+	bc.mu.Unlock()
+}
+
+// save checkpoints the progress, returning the stats and
+// the error of the broadcast or the checkpoint.
+func (bc *Broadcast) save(done bool, err error) (Stats, error) {
+	stats := bc.Stats()
+	if bc.Checkpoint == nil {
+		return stats, err
+	}
+	if serr := bc.Checkpoint.Save(bc.ID, Progress{Stats: stats, Done: done}); err == nil {
+		err = serr
+	}
+	return stats, err
+}
@@ -0,0 +1,104 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+// fakeAPI fails for some recipients and cancels the broadcast
+// after the message is sent to the stop one.
+type fakeAPI struct {
+	sent    []string
+	flooded bool
+	stop    string
+	cancel  context.CancelFunc
+}
+
+func (f *fakeAPI) Send(to tele.Recipient, what any, opts ...any) (*tele.Message, error) {
+	switch id := to.Recipient(); id {
+	case "3":
+		return nil, tele.ErrBlockedByUser
+	case "5":
+		return nil, tele.ErrUserIsDeactivated
+	case "7":
+		return nil, tele.ErrChatNotFound
+	case "4":
+		if !f.flooded {
+			f.flooded = true
+			return nil, tele.FloodError{}
+		}
+		fallthrough
+	default:
+		f.sent = append(f.sent, id)
+		if id == f.stop {
+			f.cancel()
+		}
+		return &tele.Message{}, nil
+	}
+}
+
+func (f *fakeAPI) Copy(to tele.Recipient, msg tele.Editable, opts ...any) (*tele.Message, error) {
+	return f.Send(to, msg, opts...)
+}
+
+func TestBroadcast(t *testing.T) {
+	ids := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	path := filepath.Join(t.TempDir(), "broadcasts.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	api := &fakeAPI{stop: "6", cancel: cancel}
+
+	checkpoint, err := NewFileCheckpoint(path)
+	require.NoError(t, err)
+	bc := New(api, "news", "Hello!")
+	bc.Rate = 1000
+	bc.CheckpointEvery = 2
+	bc.Checkpoint = checkpoint
+
+	var unreachable []string
+	bc.OnUnreachable = func(to tele.Recipient, err error) {
+		unreachable = append(unreachable, to.Recipient())
+	}
+
+	stats, err := bc.Run(ctx, IDs(ids))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, Stats{Sent: 4, Unreachable: 2, Flooded: 1}, stats)
+	assert.Equal(t, []string{"3", "5"}, unreachable)
+
+	// The broadcast resumes after a restart.
+	checkpoint, err = NewFileCheckpoint(path)
+	require.NoError(t, err)
+	bc = NewCopy(api, "news", &tele.Message{})
+	bc.Rate = 1000
+	bc.Checkpoint = checkpoint
+
+	var failed []string
+	bc.OnError = func(to tele.Recipient, err error) {
+		assert.True(t, errors.Is(err, tele.ErrChatNotFound))
+		failed = append(failed, to.Recipient())
+	}
+
+	stats, err = bc.Run(context.Background(), IDs(ids))
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Sent: 7, Unreachable: 2, Failed: 1, Flooded: 1}, stats)
+	assert.Equal(t, stats, bc.Stats())
+	assert.Equal(t, []string{"1", "2", "4", "6", "8", "9", "10"}, api.sent)
+	assert.Equal(t, []string{"7"}, failed)
+
+	p, err := checkpoint.Load("news")
+	require.NoError(t, err)
+	assert.True(t, p.Done)
+
+	// The finished broadcast isn't run again.
+	stats, err = bc.Run(context.Background(), IDs(ids))
+	require.NoError(t, err)
+	assert.Equal(t, 10, stats.Processed())
+	assert.Len(t, api.sent, 7)
+}
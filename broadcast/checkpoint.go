@@ -0,0 +1,100 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// Progress is the checkpointed state of the broadcast.
+type Progress struct {
+	Stats Stats `json:"stats"`
+
+	// Done tells whether the broadcast is finished.
+	Done bool `json:"done"`
+}
+
+// Checkpoint keeps the progress of broadcasts by their IDs.
+// Implement it to keep the progress in a database.
+type Checkpoint interface {
+	// Load returns the progress of the broadcast,
+	// which is empty if it's not started yet.
+	Load(id string) (Progress, error)
+
+	// Save stores the progress of the broadcast.
+	Save(id string, p Progress) error
+}
+
+// MemoryCheckpoint keeps the progress in memory.
+type MemoryCheckpoint struct {
+	mu sync.RWMutex
+	m  map[string]Progress
+}
+
+// NewMemoryCheckpoint returns an empty memory checkpoint.
+func NewMemoryCheckpoint() *MemoryCheckpoint {
+	return &MemoryCheckpoint{m: make(map[string]Progress)}
+}
+
+// Load implements Checkpoint.
+func (c *MemoryCheckpoint) Load(id string) (Progress, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.m[id], nil
+}
+
+// Save implements Checkpoint.
+func (c *MemoryCheckpoint) Save(id string, p Progress) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[id] = p
+	return nil
+}
+
+// FileCheckpoint keeps the progress in the JSON file, so it survives
+// restarts. The file is read once and rewritten on every save.
+type FileCheckpoint struct {
+	path string
+	mem  *MemoryCheckpoint
+}
+
+// NewFileCheckpoint returns the checkpoint kept in the file at the path.
+func NewFileCheckpoint(path string) (*FileCheckpoint, error) {
+	c := &FileCheckpoint{path: path, mem: NewMemoryCheckpoint()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.mem.m); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load implements Checkpoint.
+func (c *FileCheckpoint) Load(id string) (Progress, error) {
+	return c.mem.Load(id)
+}
+
+// Save implements Checkpoint.
+func (c *FileCheckpoint) Save(id string, p Progress) error {
+	c.mem.mu.Lock()
+	defer c.mem.mu.Unlock()
+	c.mem.m[id] = p
+
+	data, err := json.MarshalIndent(c.mem.m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
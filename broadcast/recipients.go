@@ -0,0 +1,69 @@
+package broadcast
+
+import tele "github.com/3JoB/telebot/v2"
+
+// Recipients iterates over the recipients of the broadcast.
+type Recipients interface {
+	// Next returns the next recipient, or nil if there are no more.
+	Next() (tele.Recipient, error)
+}
+
+// Skipper is implemented by the recipients, which can skip the
+// first ones without reading them, like a database cursor with an
+// offset. Other recipients are skipped by reading them.
+type Skipper interface {
+	Skip(n int) error
+}
+
+func skip(rs Recipients, n int) error {
+	if s, ok := rs.(Skipper); ok {
+		return s.Skip(n)
+	}
+	for ; n > 0; n-- {
+		to, err := rs.Next()
+		if err != nil || to == nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecipientsFunc is a function implementing Recipients.
+type RecipientsFunc func() (tele.Recipient, error)
+
+// Next implements Recipients.
+func (f RecipientsFunc) Next() (tele.Recipient, error) {
+	return f()
+}
+
+// Slice returns the recipients from the slice.
+func Slice(rs []tele.Recipient) Recipients {
+	return &slice{rs: rs}
+}
+
+// IDs returns the recipients with the chat IDs.
+func IDs(ids []int64) Recipients {
+	rs := make([]tele.Recipient, len(ids))
+	for i, id := range ids {
+		rs[i] = tele.ChatID(id)
+	}
+	return Slice(rs)
+}
+
+type slice struct {
+	rs []tele.Recipient
+	i  int
+}
+
+func (s *slice) Next() (tele.Recipient, error) {
+	if s.i >= len(s.rs) {
+		return nil, nil
+	}
+	s.i++
+	return s.rs[s.i-1], nil
+}
+
+func (s *slice) Skip(n int) error {
+	s.i = min(s.i+n, len(s.rs))
+	return nil
+}
@@ -0,0 +1,293 @@
+package telebot
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// MaxCallbackData is the maximum length of callback data in bytes.
+const MaxCallbackData = 64
+
+// CallbackStore keeps callback data, which doesn't fit into
+// MaxCallbackData bytes, on the bot side. Buttons carry the
+// short token instead, and the data is loaded back before
+// the handler sees Callback.Data.
+type CallbackStore interface {
+	// Save stores the data and returns its token.
+	Save(data string) (token string, err error)
+
+	// Load returns the data by its token.
+	Load(token string) (data string, err error)
+}
+
+// DefaultCallbackTTL is the time the memory store
+// keeps the callback data for by default.
+const DefaultCallbackTTL = 24 * time.Hour
+
+// NewCallbackMemoryStore returns a CallbackStore, which keeps the data
+// in memory for the ttl since it's saved last, or DefaultCallbackTTL if
+// the ttl is zero. The expired data is evicted, and its buttons are
+// rejected like the forged ones, see Settings.CallbackRejected.
+// Tokens are derived from the data itself, so the same data is
+// stored only once.
+func NewCallbackMemoryStore(ttl time.Duration) CallbackStore {
+	if ttl <= 0 {
+		ttl = DefaultCallbackTTL
+	}
+	return &callbackMemoryStore{ttl: ttl, m: make(map[string]callbackEntry)}
+}
+
+type callbackMemoryStore struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	m     map[string]callbackEntry
+	sweep time.Time
+}
+
+type callbackEntry struct {
+	data    string
+	expires time.Time
+}
+
+func (s *callbackMemoryStore) Save(data string) (string, error) {
+	sum := sha256.Sum256([]byte(data))
+	token := base64.RawURLEncoding.EncodeToString(sum[:12])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.After(s.sweep) {
+		for t, e := range s.m {
+			if now.After(e.expires) {
+				delete(s.m, t)
+			}
+		}
+		s.sweep = now.Add(s.ttl)
+	}
+	s.m[token] = callbackEntry{data: data, expires: now.Add(s.ttl)}
+	return token, nil
+}
+
+func (s *callbackMemoryStore) Load(token string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.m[token]
+	if !ok || time.Now().After(e.expires) {
+		return "", ErrCallbackDataExpired
+	}
+	return e.data, nil
+}
+
+// callbackToken marks the callback data saved in the CallbackStore.
+const callbackToken = '\v'
+
+// compactCallback replaces the too long callback data with a token.
+func (b *Bot) compactCallback(data string) string {
+	if len(data) <= MaxCallbackData || b.callbackStore == nil {
+		return data
+	}
+
+	token, err := b.callbackStore.Save(data)
+	if err != nil {
+		b.debug(err)
+		return data
+	}
+	return string(callbackToken) + token
+}
+
+// expandCallback loads the callback data saved in the CallbackStore.
+func (b *Bot) expandCallback(data string) (string, error) {
+	if data == "" || data[0] != callbackToken || b.callbackStore == nil {
+		return data, nil
+	}
+	return b.callbackStore.Load(data[1:])
+}
+
+// MarshalCallback encodes the value to the compact callback data.
+// Exported fields of structs are encoded by their order, without
+// names, so a field must only be appended to keep the data of
+// already sent buttons decodable. Fields tagged with `tele:"-"`
+// are skipped, as in Bind.
+//
+// Supported types are booleans, integers, floats, strings, byte
+// slices, and structs, slices and pointers of them.
+func MarshalCallback(v any) (string, error) {
+	buf, err := appendCallback(nil, reflect.ValueOf(v))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// UnmarshalCallback decodes the data encoded by MarshalCallback
+// to the value pointed by v.
+func UnmarshalCallback(data string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("telebot: callback value must be a non-nil pointer")
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	rest, err := readCallback(buf, rv.Elem())
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errBadCallbackData
+	}
+	return nil
+}
+
+var errBadCallbackData = errors.New("telebot: malformed callback data")
+
+// callbackField reports whether the struct field is encoded.
+func callbackField(f reflect.StructField) bool {
+	return f.IsExported() && f.Tag.Get("tele") != "-"
+}
+
+func appendCallback(buf []byte, v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(buf, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		// Reversed bytes make the varint of usual values shorter.
+		return binary.AppendUvarint(buf, bits.ReverseBytes64(math.Float64bits(v.Float()))), nil
+	case reflect.String:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...), nil
+	case reflect.Slice:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append(buf, v.Bytes()...), nil
+		}
+		fallthrough
+	case reflect.Array:
+		var err error
+		for i := 0; i < v.Len() && err == nil; i++ {
+			buf, err = appendCallback(buf, v.Index(i))
+		}
+		return buf, err
+	case reflect.Struct:
+		var err error
+		t := v.Type()
+		for i := 0; i < v.NumField() && err == nil; i++ {
+			if callbackField(t.Field(i)) {
+				buf, err = appendCallback(buf, v.Field(i))
+			}
+		}
+		return buf, err
+	case reflect.Pointer:
+		if v.IsNil() {
+			return append(buf, 0), nil
+		}
+		return appendCallback(append(buf, 1), v.Elem())
+	default:
+		return nil, errors.New("telebot: unsupported callback value type " + v.Type().String())
+	}
+}
+
+func readCallback(buf []byte, v reflect.Value) ([]byte, error) {
+	uvarint := func() (uint64, bool) {
+		x, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return 0, false
+		}
+		buf = buf[n:]
+		return x, true
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if len(buf) == 0 {
+			return nil, errBadCallbackData
+		}
+		v.SetBool(buf[0] != 0)
+		return buf[1:], nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(buf)
+		if n <= 0 {
+			return nil, errBadCallbackData
+		}
+		v.SetInt(x)
+		return buf[n:], nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, ok := uvarint()
+		if !ok {
+			return nil, errBadCallbackData
+		}
+		v.SetUint(x)
+		return buf, nil
+	case reflect.Float32, reflect.Float64:
+		x, ok := uvarint()
+		if !ok {
+			return nil, errBadCallbackData
+		}
+		v.SetFloat(math.Float64frombits(bits.ReverseBytes64(x)))
+		return buf, nil
+	case reflect.String:
+		n, ok := uvarint()
+		if !ok || n > uint64(len(buf)) {
+			return nil, errBadCallbackData
+		}
+		v.SetString(string(buf[:n]))
+		return buf[n:], nil
+	case reflect.Slice:
+		n, ok := uvarint()
+		if !ok || n > uint64(len(buf)) {
+			return nil, errBadCallbackData
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte(nil), buf[:n]...))
+			return buf[n:], nil
+		}
+		v.Set(reflect.MakeSlice(v.Type(), int(n), int(n)))
+		fallthrough
+	case reflect.Array:
+		var err error
+		for i := 0; i < v.Len() && err == nil; i++ {
+			buf, err = readCallback(buf, v.Index(i))
+		}
+		return buf, err
+	case reflect.Struct:
+		var err error
+		t := v.Type()
+		for i := 0; i < v.NumField() && err == nil; i++ {
+			if callbackField(t.Field(i)) {
+				buf, err = readCallback(buf, v.Field(i))
+			}
+		}
+		return buf, err
+	case reflect.Pointer:
+		if len(buf) == 0 {
+			return nil, errBadCallbackData
+		}
+		if buf[0] == 0 {
+			v.SetZero()
+			return buf[1:], nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return readCallback(buf[1:], v.Elem())
+	default:
+		return nil, errors.New("telebot: unsupported callback value type " + v.Type().String())
+	}
+}
@@ -0,0 +1,98 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalCallback(t *testing.T) {
+	type item struct {
+		ID     int64
+		Page   uint8
+		Price  float64
+		Name   string
+		Tags   []string
+		Parent *item
+		Raw    []byte
+		OK     bool
+		Skip   string `tele:"-"`
+		hidden int
+	}
+
+	in := item{
+		ID: -1234567, Page: 3, Price: 9.5, Name: "тест",
+		Tags: []string{"a", "b"}, Parent: &item{ID: 1}, Raw: []byte{0xff}, OK: true,
+	}
+	data, err := MarshalCallback(in)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 44)
+
+	var out item
+	require.NoError(t, UnmarshalCallback(data, &out))
+	in.Parent.Tags = []string{}
+	assert.Equal(t, in, out)
+
+	in.Skip = "skipped"
+	skipped, err := MarshalCallback(in)
+	require.NoError(t, err)
+	assert.Equal(t, data, skipped)
+
+	assert.Error(t, UnmarshalCallback(data+"AA", &out))
+	assert.Error(t, UnmarshalCallback(data[:len(data)-4], &out))
+	assert.Error(t, UnmarshalCallback(data, out))
+
+	_, err = MarshalCallback(map[string]int{})
+	assert.Error(t, err)
+}
+
+func TestCallbackStore(t *testing.T) {
+	var answered []string
+	store := NewCallbackMemoryStore(0)
+	b := newFakeBot(t, Settings{Synchronous: true, CallbackStore: store}, func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		answered = append(answered, params.Text)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	long := strings.Repeat("x", MaxCallbackData)
+	keys := [][]InlineButton{{{Unique: "long", Data: long}, {Unique: "short", Data: "1"}}}
+	b.processButtons(keys)
+	assert.Equal(t, "\fshort|1", keys[0][1].Data)
+	assert.True(t, strings.HasPrefix(keys[0][0].Data, "\v"))
+	assert.LessOrEqual(t, len(keys[0][0].Data), MaxCallbackData)
+
+	var got string
+	b.Handle(&Btn{Unique: "long"}, func(c *Context) error {
+		got = c.Data()
+		return nil
+	})
+	b.ProcessUpdate(Update{Callback: &Callback{Data: keys[0][0].Data}})
+	assert.Equal(t, long, got)
+	assert.Empty(t, answered)
+
+	// The expired data is answered with the alert.
+	got = ""
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "\vunknown"}})
+	assert.Empty(t, got)
+	assert.Equal(t, []string{"This button is no longer valid."}, answered)
+
+	// The data is evicted after the ttl.
+	store = NewCallbackMemoryStore(time.Millisecond)
+	token, err := store.Save(long)
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = store.Load(token)
+	assert.ErrorIs(t, err, ErrCallbackDataExpired)
+	_, err = store.Save("y")
+	require.NoError(t, err)
+	assert.Len(t, store.(*callbackMemoryStore).m, 1)
+}
@@ -0,0 +1,85 @@
+package telebot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"time"
+)
+
+// callbackSigned marks the signed callback data, which is followed by
+// the base64-encoded expiry and signature, and then the data itself.
+const callbackSigned = '\x0e'
+
+const (
+	callbackMACSize  = 12
+	callbackSigSize  = 4 + callbackMACSize
+	callbackSigChars = (callbackSigSize*8 + 5) / 6
+)
+
+// Signed returns a copy of the button, which callback data is signed
+// with Settings.CallbackSecret. The data is valid for the ttl, or
+// forever if it's zero. Handlers registered for the signed button
+// reject callbacks with unsigned data.
+func (b Btn) Signed(ttl time.Duration) Btn {
+	b.Sign, b.TTL = true, ttl
+	return b
+}
+
+// signCallback signs the callback data, if the button requires it.
+func (b *Bot) signCallback(data string, ttl time.Duration) string {
+	var sig [callbackSigSize]byte
+	if ttl != 0 {
+		binary.BigEndian.PutUint32(sig[:4], uint32(time.Now().Add(ttl).Unix()))
+	}
+	copy(sig[4:], b.callbackMAC(sig[:4], data))
+	return string(callbackSigned) + base64.RawURLEncoding.EncodeToString(sig[:]) + data
+}
+
+// verifyCallback checks the signature and the expiry of the data,
+// returning the data without them. Unsigned data is returned as is.
+func (b *Bot) verifyCallback(data string) (_ string, signed bool, _ error) {
+	if data == "" || data[0] != callbackSigned {
+		return data, false, nil
+	}
+	if len(b.callbackSecret) == 0 || len(data) < 1+callbackSigChars {
+		return "", true, ErrCallbackForged
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(data[1 : 1+callbackSigChars])
+	if err != nil || len(sig) != callbackSigSize {
+		return "", true, ErrCallbackForged
+	}
+
+	data = data[1+callbackSigChars:]
+	if !hmac.Equal(sig[4:], b.callbackMAC(sig[:4], data)) {
+		return "", true, ErrCallbackForged
+	}
+	if exp := binary.BigEndian.Uint32(sig[:4]); exp != 0 && time.Now().Unix() > int64(exp) {
+		return "", true, ErrCallbackExpired
+	}
+
+	return data, true, nil
+}
+
+func (b *Bot) callbackMAC(expiry []byte, data string) []byte {
+	mac := hmac.New(sha256.New, b.callbackSecret)
+	mac.Write(expiry)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)[:callbackMACSize]
+}
+
+// rejectCallback answers the callback, which failed verification.
+func (b *Bot) rejectCallback(c *Context, err error) {
+	b.debug(err)
+
+	resp := b.callbackRejected
+	if resp == nil {
+		resp = &CallbackResponse{Text: "This button is no longer valid.", ShowAlert: true}
+	}
+	cp := *resp
+	if err := b.Respond(c.u.Callback, &cp); err != nil {
+		b.OnError(err, c)
+	}
+}
@@ -0,0 +1,79 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedCallback(t *testing.T) {
+	var answers []CallbackResponse
+	b := newFakeBot(t, Settings{
+		Synchronous:    true,
+		CallbackSecret: []byte("secret"),
+	}, func(w http.ResponseWriter, r *http.Request) {
+		var resp CallbackResponse
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&resp))
+		answers = append(answers, resp)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	markup := &ReplyMarkup{}
+	btn := markup.Data("Delete", "delete", "42").Signed(time.Hour)
+
+	var got []string
+	b.Handle(&btn, func(c *Context) error {
+		got = append(got, c.Data())
+		return nil
+	})
+
+	markup.Inline(markup.Row(btn, markup.Data("Expired", "delete", "1").Signed(-time.Second)))
+	b.processButtons(markup.InlineKeyboard)
+	signed, expired := markup.InlineKeyboard[0][0].Data, markup.InlineKeyboard[0][1].Data
+	assert.LessOrEqual(t, len(signed), MaxCallbackData)
+
+	press := func(data string) {
+		b.ProcessUpdate(Update{Callback: &Callback{ID: "1", Data: data}})
+	}
+
+	press(signed)
+	assert.Equal(t, []string{"42"}, got)
+	assert.Empty(t, answers)
+
+	// Tampered, unsigned and expired data is rejected.
+	press(signed[:len(signed)-2] + "43")
+	press("\fdelete|43")
+	press(expired)
+	assert.Equal(t, []string{"42"}, got)
+	require.Len(t, answers, 3)
+	assert.True(t, answers[0].ShowAlert)
+
+	v, _, err := b.verifyCallback(expired)
+	assert.Empty(t, v)
+	assert.ErrorIs(t, err, ErrCallbackExpired)
+
+	_, _, err = b.verifyCallback(signed[:len(signed)-2] + "43")
+	assert.ErrorIs(t, err, ErrCallbackForged)
+
+	// Inline buttons are enforced the same way.
+	inline := &InlineButton{Unique: "ban", Data: "7", Sign: true}
+	b.Handle(inline, func(c *Context) error {
+		got = append(got, c.Data())
+		return nil
+	})
+
+	got, answers = nil, nil
+	press("\fban|8")
+	assert.Empty(t, got)
+	require.Len(t, answers, 1)
+
+	keys := [][]InlineButton{{*inline}}
+	b.processButtons(keys)
+	press(keys[0][0].Data)
+	assert.Equal(t, []string{"7"}, got)
+	assert.Len(t, answers, 1)
+}
@@ -0,0 +1,257 @@
+package telebot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// CommandOption configures the command registered with Bot.Command.
+type CommandOption func(*registeredCommand)
+
+type registeredCommand struct {
+	text        string
+	description string
+	scopes      []CommandScope
+	locales     map[string]string
+	middleware  []HandlerFunc
+}
+
+// Describe sets the description of the command, 3-256 characters.
+// Commands without a description are handled, but not published.
+func Describe(description string) CommandOption {
+	return func(c *registeredCommand) {
+		c.description = description
+	}
+}
+
+// Scope sets the scopes the command is published in, the default scope
+// by default. Scopes are either CommandScopeType or CommandScope values:
+//
+//	tele.Scope(tele.CommandScopeAllChatAdmin, tele.CommandScope{Type: tele.CommandScopeChat, ChatID: id})
+func Scope(scopes ...any) CommandOption {
+	return func(c *registeredCommand) {
+		for _, scope := range scopes {
+			switch scope := scope.(type) {
+			case CommandScopeType:
+				c.scopes = append(c.scopes, CommandScope{Type: scope})
+			case CommandScope:
+				c.scopes = append(c.scopes, scope)
+			}
+		}
+	}
+}
+
+// Locales sets the descriptions of the command by the language codes.
+// Other commands of the same scope get their default description in
+// these languages, since Telegram shows a localized list as a whole.
+func Locales(descriptions map[string]string) CommandOption {
+	return func(c *registeredCommand) {
+		c.locales = descriptions
+	}
+}
+
+// CommandMiddleware sets the middleware of the command handler.
+func CommandMiddleware(m ...HandlerFunc) CommandOption {
+	return func(c *registeredCommand) {
+		c.middleware = append(c.middleware, m...)
+	}
+}
+
+// Command registers the handler of the command and adds the command to
+// the list published by SyncCommands, which Start calls on startup:
+//
+//	b.Command("/ban", onBan,
+//		tele.Describe("Ban the user"),
+//		tele.Scope(tele.CommandScopeAllChatAdmin),
+//		tele.Locales(map[string]string{"ru": "Забанить пользователя"}),
+//	)
+//
+// It panics if a description isn't 3-256 characters long.
+func (b *Bot) Command(text string, h HandlerFunc, opts ...CommandOption) {
+	cmd := &registeredCommand{text: text}
+	for _, opt := range opts {
+		opt(cmd)
+	}
+	if cmd.description != "" {
+		checkDescription(text, cmd.description)
+	}
+	for _, d := range cmd.locales {
+		checkDescription(text, d)
+	}
+	if len(cmd.scopes) == 0 {
+		cmd.scopes = []CommandScope{{Type: CommandScopeDefault}}
+	}
+
+	b.Handle(text, h, cmd.middleware...)
+
+	if i := slices.IndexFunc(b.commands, func(c *registeredCommand) bool {
+		return c.text == text
+	}); i >= 0 {
+		b.commands[i] = cmd
+	} else {
+		b.commands = append(b.commands, cmd)
+	}
+}
+
+// checkDescription panics if the description of the command
+// has the length Telegram doesn't accept.
+func checkDescription(text, description string) {
+	if n := utf8.RuneCountInString(description); n < 3 || n > 256 {
+		panic(fmt.Sprintf("telebot: description of command %s is %d characters long, not 3-256", text, n))
+	}
+}
+
+// commandKey identifies the list of commands by its scope and language.
+type commandKey struct {
+	scope    CommandScope
+	language string
+}
+
+// commandList is the list of commands published in the scope and language.
+type commandList struct {
+	commandKey
+	commands []Command
+}
+
+// commandLists groups the registered commands by scopes and languages.
+func (b *Bot) commandLists() []*commandList {
+	var lists []*commandList
+	list := func(scope CommandScope, language string) *commandList {
+		for _, l := range lists {
+			if l.scope == scope && l.language == language {
+				return l
+			}
+		}
+		l := &commandList{commandKey: commandKey{scope: scope, language: language}}
+		lists = append(lists, l)
+		return l
+	}
+
+	for _, cmd := range b.commands {
+		if cmd.description == "" {
+			continue
+		}
+		for _, scope := range cmd.scopes {
+			list(scope, "")
+			languages := make([]string, 0, len(cmd.locales))
+			for language := range cmd.locales {
+				languages = append(languages, language)
+			}
+			slices.Sort(languages)
+			for _, language := range languages {
+				list(scope, language)
+			}
+		}
+	}
+
+	for _, l := range lists {
+		for _, cmd := range b.commands {
+			if cmd.description == "" || !slices.Contains(cmd.scopes, l.scope) {
+				continue
+			}
+			description := cmd.description
+			if d, ok := cmd.locales[l.language]; ok && l.language != "" {
+				description = d
+			}
+			l.commands = append(l.commands, Command{
+				Text:        strings.TrimPrefix(cmd.text, "/"),
+				Description: description,
+			})
+		}
+	}
+	return lists
+}
+
+// knownCommandKeys returns the lists the bot might have published:
+// the common scopes and the scopes of the registered commands in the
+// default language and the languages of their locales.
+func (b *Bot) knownCommandKeys() []commandKey {
+	scopes := []CommandScope{
+		{Type: CommandScopeDefault},
+		{Type: CommandScopeAllPrivateChats},
+		{Type: CommandScopeAllGroupChats},
+		{Type: CommandScopeAllChatAdmin},
+	}
+	languages := []string{""}
+	for _, cmd := range b.commands {
+		for _, scope := range cmd.scopes {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		for language := range cmd.locales {
+			if !slices.Contains(languages, language) {
+				languages = append(languages, language)
+			}
+		}
+	}
+	slices.Sort(languages)
+
+	keys := make([]commandKey, 0, len(scopes)*len(languages))
+	for _, scope := range scopes {
+		for _, language := range languages {
+			keys = append(keys, commandKey{scope: scope, language: language})
+		}
+	}
+	return keys
+}
+
+// SyncCommands publishes the commands registered with Bot.Command.
+// The current list of every scope and language is fetched first,
+// and only the changed ones are set. The lists published by the
+// previous call, which have no commands anymore, are deleted.
+//
+// On the first call, the lists published before the restart are
+// looked up in the common scopes and the scopes and languages of
+// the registered commands, and deleted if they have no commands
+// anymore. The lists of other chats and languages aren't known
+// and must be deleted with DeleteCommands.
+func (b *Bot) SyncCommands() error {
+	lists := b.commandLists()
+	for _, l := range lists {
+		current, err := b.Commands(l.scope, l.language)
+		if err != nil {
+			return err
+		}
+		if slices.Equal(current, l.commands) {
+			continue
+		}
+		if err := b.SetCommands(l.commands, l.scope, l.language); err != nil {
+			return err
+		}
+	}
+
+	synced := make([]commandKey, len(lists))
+	for i, l := range lists {
+		synced[i] = l.commandKey
+	}
+
+	stale := b.synced
+	if stale == nil {
+		for _, key := range b.knownCommandKeys() {
+			if slices.Contains(synced, key) {
+				continue
+			}
+			current, err := b.Commands(key.scope, key.language)
+			if err != nil {
+				return err
+			}
+			if len(current) > 0 {
+				stale = append(stale, key)
+			}
+		}
+	}
+
+	for _, key := range stale {
+		if slices.Contains(synced, key) {
+			continue
+		}
+		if err := b.DeleteCommands(key.scope, key.language); err != nil {
+			return err
+		}
+	}
+	b.synced = synced
+	return nil
+}
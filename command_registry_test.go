@@ -0,0 +1,100 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotCommand(t *testing.T) {
+	published := map[string][]Command{
+		"default/":         {{Text: "start", Description: "Start the bot"}},
+		"all_group_chats/": {{Text: "old", Description: "Removed before the restart"}},
+	}
+	var set, deleted []string
+
+	b := newFakeBot(t, Settings{Synchronous: true}, func(w http.ResponseWriter, r *http.Request) {
+		method := fakeMethod(r)
+
+		var params CommandParams
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		require.NotNil(t, params.Scope)
+		key := params.Scope.Type + "/" + params.LanguageCode
+
+		switch method {
+		case "getMyCommands":
+			data, _ := json.Marshal(published[key])
+			w.Write([]byte(`{"ok":true,"result":` + string(data) + `}`))
+		case "setMyCommands":
+			set = append(set, key)
+			published[key] = params.Commands
+			w.Write([]byte(`{"ok":true,"result":true}`))
+		case "deleteMyCommands":
+			deleted = append(deleted, key)
+			delete(published, key)
+			w.Write([]byte(`{"ok":true,"result":true}`))
+		}
+	})
+
+	var handled string
+	handler := func(c *Context) error {
+		handled = c.Text()
+		return nil
+	}
+
+	b.Command("/start", handler, Describe("Start the bot"))
+	b.Command("/hidden", handler)
+	b.Command("/ban", handler,
+		Describe("Ban the user"),
+		Scope(CommandScopeAllChatAdmin, CommandScopeDefault),
+		Locales(map[string]string{"ru": "Забанить"}),
+	)
+
+	require.NoError(t, b.SyncCommands())
+	assert.Equal(t, []string{"default/", "all_chat_administrators/", "all_chat_administrators/ru", "default/ru"}, set)
+	assert.Equal(t, []Command{
+		{Text: "start", Description: "Start the bot"},
+		{Text: "ban", Description: "Ban the user"},
+	}, published["default/"])
+	assert.Equal(t, []Command{
+		{Text: "start", Description: "Start the bot"},
+		{Text: "ban", Description: "Забанить"},
+	}, published["default/ru"])
+	assert.Equal(t, []Command{
+		{Text: "ban", Description: "Забанить"},
+	}, published["all_chat_administrators/ru"])
+
+	// The lists published before the restart are deleted on the first sync.
+	assert.Equal(t, []string{"all_group_chats/"}, deleted)
+	assert.NotContains(t, published, "all_group_chats/")
+
+	set, deleted = nil, nil
+	require.NoError(t, b.SyncCommands())
+	assert.Empty(t, set)
+	assert.Empty(t, deleted)
+
+	b.ProcessUpdate(Update{Message: &Message{Text: "/hidden"}})
+	assert.Equal(t, "/hidden", handled)
+
+	// The lists left without commands are deleted.
+	var mw bool
+	b.Command("/ban", handler, Describe("Ban the user"), CommandMiddleware(func(c *Context) error {
+		mw = true
+		return c.Next()
+	}))
+	require.NoError(t, b.SyncCommands())
+	assert.Equal(t, []string{"all_chat_administrators/", "all_chat_administrators/ru", "default/ru"}, deleted)
+	assert.Len(t, published, 1)
+
+	b.ProcessUpdate(Update{Message: &Message{Text: "/ban"}})
+	assert.True(t, mw)
+
+	assert.Panics(t, func() { b.Command("/ok", handler, Describe("ok")) })
+	assert.Panics(t, func() {
+		b.Command("/ok", handler, Describe("Fine"), Locales(map[string]string{"ru": strings.Repeat("я", 257)}))
+	})
+}
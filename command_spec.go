@@ -0,0 +1,455 @@
+package telebot
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Types of command arguments. The type of an argument may be a union,
+// like "mention|id", in which case the first matching one is used.
+const (
+	ArgString   = "string"   // string, the default one
+	ArgInt      = "int"      // int64
+	ArgFloat    = "float"    // float64
+	ArgBool     = "bool"     // bool
+	ArgDuration = "duration" // time.Duration
+	ArgID       = "id"       // int64, the ID of a user or chat
+	ArgMention  = "mention"  // *User, an @username or a text mention
+)
+
+// CommandSpec is a declarative specification of a command and its
+// arguments, which parses the arguments and generates the usage:
+//
+//	spec := tele.MustCommandSpec("/ban <user:mention|id> [duration:duration] [reason...] [--silent]", "Ban the user")
+//	spec.Handle(b, func(c *tele.Context, args *tele.CommandArgs) error {
+//		user := args.User("user")
+//		...
+//	})
+//
+// Required arguments are in angle brackets, optional ones in square
+// brackets. The last argument may end with "...", taking the rest of
+// the text. Flags start with "--", and are booleans unless a type is
+// given: [--reason:string]. Arguments with spaces can be quoted.
+type CommandSpec struct {
+	// Command is the command with the slash, like "/ban".
+	Command string
+
+	// Description is the description of the command.
+	Description string
+
+	Args  []ArgSpec
+	Flags []ArgSpec
+
+	spec string
+}
+
+// ArgSpec is a specification of a single command argument or flag.
+type ArgSpec struct {
+	Name     string
+	Types    []string
+	Optional bool
+	Variadic bool
+}
+
+// NewCommandSpec parses the command spec.
+func NewCommandSpec(spec, description string) (*CommandSpec, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return nil, errors.New("telebot: command spec must start with the command")
+	}
+
+	s := &CommandSpec{
+		Command:     fields[0],
+		Description: description,
+		spec:        strings.Join(fields, " "),
+	}
+
+	for _, field := range fields[1:] {
+		var arg ArgSpec
+		switch {
+		case strings.HasPrefix(field, "<") && strings.HasSuffix(field, ">"):
+		case strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]"):
+			arg.Optional = true
+		default:
+			return nil, errors.New("telebot: bad command spec argument " + field)
+		}
+
+		name, types, _ := strings.Cut(field[1:len(field)-1], ":")
+		if arg.Variadic = strings.HasSuffix(name+types, "..."); arg.Variadic {
+			name, types = strings.TrimSuffix(name, "..."), strings.TrimSuffix(types, "...")
+		}
+		arg.Name = name
+		if types != "" {
+			arg.Types = strings.Split(types, "|")
+		}
+		for _, typ := range arg.Types {
+			if _, ok := argParsers[typ]; !ok {
+				return nil, errors.New("telebot: unknown command argument type " + typ)
+			}
+		}
+
+		if strings.HasPrefix(name, "--") {
+			if !arg.Optional || arg.Variadic {
+				return nil, errors.New("telebot: flag " + name + " must be optional")
+			}
+			arg.Name = name[2:]
+			s.Flags = append(s.Flags, arg)
+			continue
+		}
+
+		if n := len(s.Args); n > 0 {
+			last := s.Args[n-1]
+			if last.Variadic {
+				return nil, errors.New("telebot: only the last argument can be variadic")
+			}
+			if last.Optional && !arg.Optional {
+				return nil, errors.New("telebot: required argument " + name + " after optional one")
+			}
+		}
+		s.Args = append(s.Args, arg)
+	}
+
+	return s, nil
+}
+
+// MustCommandSpec is like NewCommandSpec, but panics on errors.
+func MustCommandSpec(spec, description string) *CommandSpec {
+	s, err := NewCommandSpec(spec, description)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Usage returns the usage of the command, the spec itself.
+func (s *CommandSpec) Usage() string {
+	return s.spec
+}
+
+// BotCommand returns the command for SetCommands, which
+// description is followed by the usage of the arguments.
+func (s *CommandSpec) BotCommand() Command {
+	desc := s.Description
+	if _, args, ok := strings.Cut(s.spec, " "); ok {
+		if desc != "" {
+			desc += " "
+		}
+		desc += args
+	}
+	if utf8.RuneCountInString(desc) > 256 {
+		desc = string([]rune(desc)[:256])
+	}
+	return Command{Text: strings.TrimPrefix(s.Command, "/"), Description: desc}
+}
+
+// Handle registers the handler of the command, which receives the parsed
+// arguments. Parse errors are passed to Settings.OnBindError.
+func (s *CommandSpec) Handle(b *Bot, h func(c *Context, args *CommandArgs) error, m ...HandlerFunc) {
+	b.Handle(s.Command, func(c *Context) error {
+		args, err := s.Parse(c.Message())
+		if err != nil {
+			var usageErr *UsageError
+			if errors.As(err, &usageErr) {
+				return c.b.onBindError(c, err)
+			}
+			return err
+		}
+		return h(c, args)
+	}, m...)
+}
+
+// UsageError is returned by CommandSpec.Parse, if the arguments don't
+// match the spec. Its text is followed by the usage of the command.
+type UsageError struct {
+	Spec *CommandSpec
+	Err  error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error() + "\nUsage: " + e.Spec.Usage()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// CommandArgs are the parsed arguments of a command.
+type CommandArgs struct {
+	values map[string]any
+}
+
+// Has reports whether the argument or flag is given.
+func (a *CommandArgs) Has(name string) bool {
+	_, ok := a.values[name]
+	return ok
+}
+
+// Get returns the value of the argument or flag, or nil if it's not given.
+func (a *CommandArgs) Get(name string) any {
+	return a.values[name]
+}
+
+// String returns the string argument.
+func (a *CommandArgs) String(name string) string {
+	s, _ := a.values[name].(string)
+	return s
+}
+
+// Int returns the int or id argument.
+func (a *CommandArgs) Int(name string) int64 {
+	n, _ := a.values[name].(int64)
+	return n
+}
+
+// Float returns the float argument.
+func (a *CommandArgs) Float(name string) float64 {
+	n, _ := a.values[name].(float64)
+	return n
+}
+
+// Bool returns the bool argument or flag.
+func (a *CommandArgs) Bool(name string) bool {
+	b, _ := a.values[name].(bool)
+	return b
+}
+
+// Duration returns the duration argument.
+func (a *CommandArgs) Duration(name string) time.Duration {
+	d, _ := a.values[name].(time.Duration)
+	return d
+}
+
+// User returns the mention or id argument as a user. The user
+// mentioned by @username has only the Username field set.
+func (a *CommandArgs) User(name string) *User {
+	switch v := a.values[name].(type) {
+	case *User:
+		return v
+	case int64:
+		return &User{ID: v}
+	}
+	return nil
+}
+
+// Parse parses the arguments of the command message.
+// Errors are of *UsageError type.
+func (s *CommandSpec) Parse(m *Message) (*CommandArgs, error) {
+	if m == nil {
+		return nil, ErrBadContext
+	}
+
+	tokens, err := tokenizeCommand(m.Text)
+	if err != nil {
+		return nil, &UsageError{Spec: s, Err: err}
+	}
+	if len(tokens) > 0 {
+		tokens = tokens[1:] // the command itself
+	}
+
+	args := &CommandArgs{values: make(map[string]any)}
+	var positional []commandToken
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.quoted || !strings.HasPrefix(tok.text, "--") || len(tok.text) == 2 {
+			positional = append(positional, tok)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(tok.text[2:], "=")
+		flag := s.flag(name)
+		if flag == nil {
+			return nil, &UsageError{Spec: s, Err: errors.New("unknown flag --" + name)}
+		}
+		if len(flag.Types) == 0 {
+			if hasValue {
+				return nil, &UsageError{Spec: s, Err: errors.New("flag --" + name + " has no value")}
+			}
+			args.values[flag.Name] = true
+			continue
+		}
+
+		vtok := commandToken{text: value}
+		if !hasValue {
+			if i+1 == len(tokens) {
+				return nil, &UsageError{Spec: s, Err: errors.New("missing value of flag --" + name)}
+			}
+			i++
+			vtok = tokens[i]
+		}
+		v, err := parseArg(*flag, vtok, m.Entities)
+		if err != nil {
+			return nil, &UsageError{Spec: s, Err: err}
+		}
+		args.values[flag.Name] = v
+	}
+
+	// An optional argument, which doesn't match the token, is skipped,
+	// and the token is tried as the next argument.
+	i := 0
+	for j, arg := range s.Args {
+		if i == len(positional) {
+			if !arg.Optional {
+				return nil, &UsageError{Spec: s, Err: errors.New("missing " + arg.Name)}
+			}
+			break
+		}
+
+		tok := positional[i]
+		if arg.Variadic {
+			rest := make([]string, 0, len(positional)-i)
+			for _, t := range positional[i:] {
+				rest = append(rest, t.text)
+			}
+			tok = commandToken{text: strings.Join(rest, " "), offset: tok.offset}
+		}
+
+		v, err := parseArg(arg, tok, m.Entities)
+		if err != nil {
+			if arg.Optional && j+1 < len(s.Args) {
+				continue
+			}
+			return nil, &UsageError{Spec: s, Err: err}
+		}
+		args.values[arg.Name] = v
+
+		if i++; arg.Variadic {
+			i = len(positional)
+		}
+	}
+
+	if i < len(positional) {
+		return nil, &UsageError{Spec: s, Err: errors.New("too many arguments")}
+	}
+	return args, nil
+}
+
+func (s *CommandSpec) flag(name string) *ArgSpec {
+	for i := range s.Flags {
+		if s.Flags[i].Name == name {
+			return &s.Flags[i]
+		}
+	}
+	return nil
+}
+
+type argParser func(tok commandToken, entities Entities) (any, bool)
+
+var argParsers = map[string]argParser{
+	ArgString: func(tok commandToken, _ Entities) (any, bool) {
+		return tok.text, true
+	},
+	ArgInt: func(tok commandToken, _ Entities) (any, bool) {
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		return n, err == nil
+	},
+	ArgID: func(tok commandToken, _ Entities) (any, bool) {
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		return n, err == nil && n != 0
+	},
+	ArgFloat: func(tok commandToken, _ Entities) (any, bool) {
+		n, err := strconv.ParseFloat(tok.text, 64)
+		return n, err == nil
+	},
+	ArgBool: func(tok commandToken, _ Entities) (any, bool) {
+		b, err := strconv.ParseBool(tok.text)
+		return b, err == nil
+	},
+	ArgDuration: func(tok commandToken, _ Entities) (any, bool) {
+		d, err := time.ParseDuration(tok.text)
+		return d, err == nil
+	},
+	ArgMention: func(tok commandToken, entities Entities) (any, bool) {
+		if !tok.quoted {
+			for _, e := range entities {
+				if e.Type == EntityTMention && e.User != nil && e.Offset == tok.offset {
+					return e.User, true
+				}
+			}
+		}
+		if len(tok.text) > 1 && tok.text[0] == '@' && !strings.ContainsAny(tok.text, " \t\n") {
+			return &User{Username: tok.text[1:]}, true
+		}
+		return nil, false
+	},
+}
+
+func parseArg(arg ArgSpec, tok commandToken, entities Entities) (any, error) {
+	types := arg.Types
+	if len(types) == 0 {
+		types = []string{ArgString}
+	}
+	for _, typ := range types {
+		if v, ok := argParsers[typ](tok, entities); ok {
+			return v, nil
+		}
+	}
+	return nil, errors.New("invalid " + arg.Name + ": expected " + strings.Join(types, " or "))
+}
+
+// commandToken is a word of the command text. Its offset is
+// in UTF-16 code units, the same as entities have.
+type commandToken struct {
+	text   string
+	offset int
+	quoted bool
+}
+
+// tokenizeCommand splits the text by spaces,
+// keeping the quoted strings together.
+func tokenizeCommand(text string) ([]commandToken, error) {
+	var (
+		tokens []commandToken
+		tok    *commandToken
+		sb     strings.Builder
+		quote  rune
+		escape bool
+		offset int
+	)
+
+	for _, r := range text {
+		pos := offset
+		offset += utf16Len(string(r))
+
+		switch {
+		case escape:
+			sb.WriteRune(r)
+			escape = false
+		case quote != 0 && r == '\\' && quote == '"':
+			escape = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			sb.WriteRune(r)
+		case unicode.IsSpace(r):
+			if tok != nil {
+				tok.text = sb.String()
+				tokens = append(tokens, *tok)
+				tok = nil
+				sb.Reset()
+			}
+		default:
+			if tok == nil {
+				tok = &commandToken{offset: pos}
+			}
+			if (r == '"' || r == '\'') && sb.Len() == 0 {
+				quote, tok.quoted = r, true
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quoted string")
+	}
+	if tok != nil {
+		tok.text = sb.String()
+		tokens = append(tokens, *tok)
+	}
+	return tokens, nil
+}
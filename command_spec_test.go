@@ -0,0 +1,94 @@
+package telebot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCommandSpec(t *testing.T) {
+	s, err := NewCommandSpec("/ban <user:mention|id> [duration:duration] [reason...] [--silent] [--notify:int]", "Ban the user")
+	require.NoError(t, err)
+	assert.Equal(t, "/ban", s.Command)
+	assert.Equal(t, []ArgSpec{
+		{Name: "user", Types: []string{"mention", "id"}},
+		{Name: "duration", Types: []string{"duration"}, Optional: true},
+		{Name: "reason", Optional: true, Variadic: true},
+	}, s.Args)
+	assert.Equal(t, []ArgSpec{
+		{Name: "silent", Optional: true},
+		{Name: "notify", Types: []string{"int"}, Optional: true},
+	}, s.Flags)
+
+	cmd := s.BotCommand()
+	assert.Equal(t, "ban", cmd.Text)
+	assert.Equal(t, "Ban the user <user:mention|id> [duration:duration] [reason...] [--silent] [--notify:int]", cmd.Description)
+
+	long, err := NewCommandSpec("/long", strings.Repeat("ж", 300))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("ж", 256), long.BotCommand().Description)
+
+	for _, spec := range []string{
+		"ban <user>",
+		"/ban user",
+		"/ban [a] <b>",
+		"/ban [a...] [b]",
+		"/ban <a:number>",
+		"/ban <--silent>",
+	} {
+		_, err := NewCommandSpec(spec, "")
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCommandSpecParse(t *testing.T) {
+	s := MustCommandSpec("/ban <user:mention|id> [duration:duration] [reason...] [--silent] [--notify:int]", "")
+	user := &User{ID: 1, FirstName: "Иван"}
+
+	args, err := s.Parse(&Message{
+		Text:     "/ban@bot Иван 1h30m \"spam and\" flood --silent --notify 5",
+		Entities: Entities{{Type: EntityTMention, Offset: 9, Length: 4, User: user}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, user, args.User("user"))
+	assert.Equal(t, 90*time.Minute, args.Duration("duration"))
+	assert.Equal(t, "spam and flood", args.String("reason"))
+	assert.True(t, args.Bool("silent"))
+	assert.Equal(t, int64(5), args.Int("notify"))
+
+	args, err = s.Parse(&Message{Text: "/ban @durov --notify=3"})
+	require.NoError(t, err)
+	assert.Equal(t, &User{Username: "durov"}, args.User("user"))
+	assert.False(t, args.Has("duration"))
+	assert.False(t, args.Bool("silent"))
+	assert.Equal(t, int64(3), args.Int("notify"))
+
+	args, err = s.Parse(&Message{Text: "/ban 42 'quoted --silent'"})
+	require.NoError(t, err)
+	assert.Equal(t, &User{ID: 42}, args.User("user"))
+	assert.False(t, args.Has("duration"))
+	assert.Equal(t, "quoted --silent", args.String("reason"))
+
+	for text, want := range map[string]string{
+		"/ban":                   "missing user",
+		"/ban durov":             "invalid user: expected mention or id",
+		"/ban 42 \"unterminated": "unterminated quoted string",
+		"/ban 42 --force":        "unknown flag --force",
+		"/ban 42 --silent=1":     "flag --silent has no value",
+		"/ban 42 --notify":       "missing value of flag --notify",
+		"/ban 42 --notify=x":     "invalid notify: expected int",
+	} {
+		_, err := s.Parse(&Message{Text: text})
+		var usageErr *UsageError
+		require.True(t, errors.As(err, &usageErr), text)
+		assert.Equal(t, want, usageErr.Err.Error(), text)
+		assert.Contains(t, err.Error(), "\nUsage: /ban <user:mention|id>", text)
+	}
+
+	_, err = MustCommandSpec("/ping", "").Parse(&Message{Text: "/ping pong"})
+	assert.EqualError(t, err, "too many arguments\nUsage: /ping")
+}
@@ -1,6 +1,7 @@
 package telebot
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"time"
@@ -13,6 +14,8 @@ type Context struct {
 	b     *Bot
 	u     Update
 	next  bool
+	span  *updateSpan
+	album []Message
 	store *hashmap.Map[string, any]
 }
 
@@ -21,6 +24,24 @@ func (c *Context) Bot() *Bot {
 	return c.b
 }
 
+// Span returns the span tracing the current update,
+// or nil if Settings.Tracer is not set.
+func (c *Context) Span() Span {
+	if c.span == nil {
+		return nil
+	}
+	return c.span.Span
+}
+
+// traced appends the span of the update to the send options,
+// so the requests become its child spans.
+func (c *Context) traced(opts []any) []any {
+	if c.span == nil {
+		return opts
+	}
+	return append(opts[:len(opts):len(opts)], spanOption{c.span.Span})
+}
+
 // Update returns the original update.
 func (c *Context) Update() Update {
 	return c.u
@@ -47,6 +68,13 @@ func (c *Context) Message() *Message {
 	}
 }
 
+// Album returns the messages of the album sorted by their IDs
+// in OnAlbum handlers, which fire once the whole album is received
+// (see Settings.AlbumWindow). Otherwise, it returns nil.
+func (c *Context) Album() []Message {
+	return c.album
+}
+
 // Callback returns stored callback if such presented.
 func (c *Context) Callback() *Callback {
 	return c.u.Callback
@@ -233,14 +261,14 @@ func (c *Context) Args() []string {
 // Send sends a message to the current recipient.
 // See Send from bot.go.
 func (c *Context) Send(what any, opts ...any) (*Message, error) {
-	e, err := c.b.Send(c.Recipient(), what, opts...)
+	e, err := c.b.Send(c.Recipient(), what, c.traced(opts)...)
 	return e, err
 }
 
 // SendAlbum sends an album to the current recipient.
 // See SendAlbum from bot.go.
 func (c *Context) SendAlbum(a Album, opts ...any) error {
-	_, err := c.b.SendAlbum(c.Recipient(), a, opts...)
+	_, err := c.b.SendAlbum(c.Recipient(), a, c.traced(opts)...)
 	return err
 }
 
@@ -251,13 +279,13 @@ func (c *Context) Reply(what any, opts ...any) (*Message, error) {
 	if msg == nil {
 		return nil, ErrBadContext
 	}
-	return c.b.Reply(msg, what, opts...)
+	return c.b.Reply(msg, what, c.traced(opts)...)
 }
 
 // Forward forwards the given message to the current recipient.
 // See Forward from bot.go.
 func (c *Context) Forward(msg Editable, opts ...any) error {
-	_, err := c.b.Forward(c.Recipient(), msg, opts...)
+	_, err := c.b.Forward(c.Recipient(), msg, c.traced(opts)...)
 	return err
 }
 
@@ -268,7 +296,7 @@ func (c *Context) ForwardTo(to Recipient, opts ...any) error {
 	if msg == nil {
 		return ErrBadContext
 	}
-	_, err := c.b.Forward(to, msg, opts...)
+	_, err := c.b.Forward(to, msg, c.traced(opts)...)
 	return err
 }
 
@@ -276,11 +304,11 @@ func (c *Context) ForwardTo(to Recipient, opts ...any) error {
 // See Edit from bot.go.
 func (c *Context) Edit(what any, opts ...any) error {
 	if c.u.InlineResult != nil {
-		_, err := c.b.Edit(c.u.InlineResult, what, opts...)
+		_, err := c.b.Edit(c.u.InlineResult, what, c.traced(opts)...)
 		return err
 	}
 	if c.u.Callback != nil {
-		_, err := c.b.Edit(c.u.Callback, what, opts...)
+		_, err := c.b.Edit(c.u.Callback, what, c.traced(opts)...)
 		return err
 	}
 	return ErrBadContext
@@ -290,11 +318,11 @@ func (c *Context) Edit(what any, opts ...any) error {
 // See EditCaption from bot.go.
 func (c *Context) EditCaption(caption string, opts ...any) error {
 	if c.u.InlineResult != nil {
-		_, err := c.b.EditCaption(c.u.InlineResult, caption, opts...)
+		_, err := c.b.EditCaption(c.u.InlineResult, caption, c.traced(opts)...)
 		return err
 	}
 	if c.u.Callback != nil {
-		_, err := c.b.EditCaption(c.u.Callback, caption, opts...)
+		_, err := c.b.EditCaption(c.u.Callback, caption, c.traced(opts)...)
 		return err
 	}
 	return ErrBadContext
@@ -328,16 +356,27 @@ func (c *Context) Delete() error {
 	if msg == nil {
 		return ErrBadContext
 	}
-	return c.b.Delete(msg)
+	return c.b.delete(c.Span(), msg)
 }
 
 // DeleteAfter waits for the duration to elapse and then removes the
 // message. It handles an error automatically using b.OnError callback.
 // It returns a Timer that can be used to cancel the call using its Stop method.
+// The timer is lost on restart, use Bot.ScheduleDelete to keep it.
+// If the context has no message, ErrBadContext is handled at once,
+// and the returned timer is stopped.
 func (c *Context) DeleteAfter(d time.Duration) *time.Timer {
+	// The context is released before the timer fires.
+	b, msg := c.b, c.Message()
+	if msg == nil {
+		b.OnError(ErrBadContext, c)
+		t := time.NewTimer(d)
+		t.Stop()
+		return t
+	}
 	return time.AfterFunc(d, func() {
-		if err := c.Delete(); err != nil {
-			c.b.OnError(err, c)
+		if err := b.Delete(msg); err != nil {
+			b.OnError(err, nil)
 		}
 	})
 }
@@ -357,7 +396,32 @@ func (c *Context) Done() error {
 // Notify updates the chat action for the current recipient.
 // See Notify from bot.go.
 func (c *Context) Notify(action ChatAction) error {
-	return c.b.Notify(c.Recipient(), action)
+	return c.b.notify(c.Span(), c.Recipient(), action)
+}
+
+// NotifyWhile keeps the chat action for the current recipient, in the
+// topic of the message if it's sent to one, while f runs, and returns
+// the error of f. The error of the chat action is passed to OnError
+// and doesn't stop f. The action isn't resent after NotifyWhile
+// returns, but the one being sent isn't waited for.
+// See KeepNotifying from bot.go.
+//
+//	return c.NotifyWhile(tele.UploadingPhoto, func() error {
+//		photo := render()
+//		return c.Send(photo)
+//	})
+func (c *Context) NotifyWhile(action ChatAction, f func() error) error {
+	var threadID []int
+	if m := c.Message(); m != nil && m.IsTopicMessage {
+		threadID = append(threadID, m.ThreadID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := c.b.keepNotifying(ctx, c.Span(), c.Recipient(), action, threadID...); err != nil {
+		c.b.OnError(err, c)
+	}
+	return f()
 }
 
 // Ship replies to the current shipping query.
@@ -366,7 +430,7 @@ func (c *Context) Ship(what ...any) error {
 	if c.u.ShippingQuery == nil {
 		return errors.New("telebot: context shipping query is nil")
 	}
-	return c.b.Ship(c.u.ShippingQuery, what...)
+	return c.b.ship(c.Span(), c.u.ShippingQuery, what...)
 }
 
 // Accept finalizes the current deal.
@@ -375,7 +439,7 @@ func (c *Context) Accept(errorMessage ...string) error {
 	if c.u.PreCheckoutQuery == nil {
 		return errors.New("telebot: context pre checkout query is nil")
 	}
-	return c.b.Accept(c.u.PreCheckoutQuery, errorMessage...)
+	return c.b.accept(c.Span(), c.u.PreCheckoutQuery, errorMessage...)
 }
 
 // Respond sends a response for the current callback query.
@@ -384,7 +448,7 @@ func (c *Context) Respond(resp ...*CallbackResponse) error {
 	if c.u.Callback == nil {
 		return errors.New("telebot: context callback is nil")
 	}
-	return c.b.Respond(c.u.Callback, resp...)
+	return c.b.respond(c.Span(), c.u.Callback, resp...)
 }
 
 // Answer sends a response to the current inline query.
@@ -393,7 +457,7 @@ func (c *Context) Answer(resp *QueryResponse) error {
 	if c.u.Query == nil {
 		return errors.New("telebot: context inline query is nil")
 	}
-	return c.b.Answer(c.u.Query, resp)
+	return c.b.answer(c.Span(), c.u.Query, resp)
 }
 
 // Set saves data in the context.
@@ -428,5 +492,7 @@ func (n *Context) releaseContext() {
 	}
 	n.b = nil
 	n.u = Update{}
+	n.span = nil
+	n.album = nil
 	ctxPool.Put(n)
 }
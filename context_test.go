@@ -1,9 +1,16 @@
 package telebot
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var _ *Context = (*Context)(nil)
@@ -16,3 +23,93 @@ func TestContext(t *testing.T) {
 		assert.Equal(t, "Jon Snow", c.Get("name"))
 	})
 }
+
+// errorLogger passes the errors to its function.
+type errorLogger struct {
+	Logger
+	onError func(error, *Context)
+}
+
+func (l errorLogger) OnError(err error, c *Context) {
+	l.onError(err, c)
+}
+
+func TestDeleteAfter(t *testing.T) {
+	var handled []error
+	logger := errorLogger{
+		Logger: NewSlogLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		onError: func(err error, c *Context) {
+			assert.NotNil(t, c)
+			handled = append(handled, err)
+		},
+	}
+
+	deleted := make(chan struct{}, 1)
+	b := newFakeBot(t, Settings{Logger: logger}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "deleteMessage", fakeMethod(r))
+		deleted <- struct{}{}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	c := b.NewContext(Update{Message: &Message{ID: 1, Chat: &Chat{ID: 1}}})
+	c.DeleteAfter(time.Millisecond)
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		t.Fatal("the message isn't deleted")
+	}
+
+	// Without a message, the error is handled at once.
+	c = b.NewContext(Update{Poll: &Poll{}})
+	timer := c.DeleteAfter(time.Millisecond)
+	assert.False(t, timer.Stop())
+	assert.Equal(t, []error{ErrBadContext}, handled)
+}
+
+func TestNotifyWhile(t *testing.T) {
+	defer func(d time.Duration) { notifyInterval = d }(notifyInterval)
+	notifyInterval = time.Millisecond
+
+	requests := make(chan map[string]any, 100)
+	b := newFakeBot(t, Settings{}, func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]any{"method": fakeMethod(r)}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		requests <- params
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	c := b.NewContext(Update{Message: &Message{
+		Chat:           &Chat{ID: 1},
+		ThreadID:       2,
+		IsTopicMessage: true,
+	}})
+
+	// f returns after the action is resent twice.
+	var got []map[string]any
+	done := errors.New("done")
+	err := c.NotifyWhile(Typing, func() error {
+		for len(got) < 3 {
+			select {
+			case params := <-requests:
+				got = append(got, params)
+			case <-time.After(time.Second):
+				t.Fatal("the action isn't resent")
+			}
+		}
+		return done
+	})
+	assert.Equal(t, done, err)
+
+	// The action isn't resent after NotifyWhile returns,
+	// only the one being sent may still come.
+	time.Sleep(50 * notifyInterval)
+	assert.LessOrEqual(t, len(requests), 1)
+	for len(requests) > 0 {
+		got = append(got, <-requests)
+	}
+	for _, params := range got {
+		assert.Equal(t, "sendChatAction", params["method"])
+		assert.Equal(t, "typing", params["action"])
+		assert.EqualValues(t, 2, params["message_thread_id"])
+	}
+}
@@ -0,0 +1,44 @@
+package telebot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCron(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		require.NoError(t, err)
+		return tm
+	}
+	next := func(spec, from string) string {
+		c, err := ParseCron(spec)
+		require.NoError(t, err)
+		return c.Next(at(from)).Format("2006-01-02 15:04")
+	}
+
+	// 2023-11-03 is Friday.
+	assert.Equal(t, "2023-11-03 17:45", next("*/15 9-17 * * mon-fri", "2023-11-03 17:30"))
+	assert.Equal(t, "2023-11-06 09:00", next("*/15 9-17 * * mon-fri", "2023-11-03 17:45"))
+	assert.Equal(t, "2023-12-01 00:00", next("@monthly", "2023-11-03 17:30"))
+	assert.Equal(t, "2023-11-05 00:00", next("@weekly", "2023-11-03 17:30"))
+	assert.Equal(t, "2023-11-05 12:00", next("0 12 * * 7", "2023-11-03 17:30"))
+	assert.Equal(t, "2024-02-29 00:00", next("0 0 29 feb *", "2023-11-03 17:30"))
+	assert.Equal(t, "2023-11-03 19:00", next("@every 90m", "2023-11-03 17:30"))
+
+	// Both days restricted: either one matches.
+	assert.Equal(t, "2023-11-04 00:00", next("0 0 15 * sat", "2023-11-03 17:30"))
+	assert.Equal(t, "2023-11-15 00:00", next("0 0 15 * sat", "2023-11-11 00:00"))
+
+	c, err := ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, c.Next(time.Now()).IsZero())
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "@every x"} {
+		_, err := ParseCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -3,8 +3,6 @@ package telebot
 import (
 	"fmt"
 	"strings"
-
-	"github.com/jamiealquiza/fnv"
 )
 
 type (
@@ -23,6 +21,16 @@ type (
 		err        *Error
 		MigratedTo int64
 	}
+
+	// ResponseParameters describes why a request was unsuccessful.
+	ResponseParameters struct {
+		// The group has been migrated to a supergroup with the specified identifier.
+		MigrateTo int64 `json:"migrate_to_chat_id,omitempty"`
+
+		// In case of exceeding flood control, the number of seconds left
+		// to wait before the request can be repeated.
+		RetryAfter int `json:"retry_after,omitempty"`
+	}
 )
 
 // String returns description of error.
@@ -45,16 +53,38 @@ func (err *Error) Error() string {
 	return fmt.Sprintf("telegram: %s (%d)", msg, err.Code)
 }
 
+// Is reports whether err and target are the same API error, that is,
+// they share the code and the canonical description. It lets errors.Is
+// match errors, which descriptions were reworded by Telegram.
+func (err *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || err == nil || t == nil {
+		return false
+	}
+	return err.Code == t.Code &&
+		canonicalDescription(err.Code, err.Description) == canonicalDescription(t.Code, t.Description)
+}
+
 // Error implements error interface.
 func (err FloodError) Error() string {
 	return err.err.Error()
 }
 
+// Unwrap returns the underlying API error.
+func (err FloodError) Unwrap() error {
+	return err.err
+}
+
 // Error implements error interface.
 func (err GroupError) Error() string {
 	return err.err.Error()
 }
 
+// Unwrap returns the underlying API error.
+func (err GroupError) Unwrap() error {
+	return err.err
+}
+
 // NewError returns new Error instance with given description.
 // First element of msgs is Description. The second is optional Message.
 func NewError(code int, msgs ...string) *Error {
@@ -76,6 +106,13 @@ var (
 	ErrInternal     = NewError(500, "Internal Server Error")
 )
 
+// Conflict and flood errors
+var (
+	ErrTerminatedByOther = NewError(409, "Conflict: terminated by other getUpdates request; make sure that only one bot instance is running")
+	ErrWebhookActive     = NewError(409, "Conflict: can't use getUpdates method while webhook is active; use deleteWebhook to delete the webhook first")
+	ErrTooManyRequests   = NewError(429, "Too Many Requests: retry later")
+)
+
 // Bad request errors
 var (
 	ErrBadButtonData          = NewError(400, "Bad Request: BUTTON_DATA_INVALID")
@@ -119,6 +156,33 @@ var (
 	ErrWrongTypeOfContent     = NewError(400, "Bad Request: wrong type of the web page content")
 	ErrWrongURL               = NewError(400, "Bad Request: wrong HTTP URL specified")
 	ErrForwardMessage         = NewError(400, "Bad Request: administrators of the chat restricted message forwarding")
+	ErrBadMessageID           = NewError(400, "Bad Request: MESSAGE_ID_INVALID", "Message ID is invalid")
+	ErrBadPeerID              = NewError(400, "Bad Request: PEER_ID_INVALID", "Peer ID is invalid")
+	ErrBadUserID              = NewError(400, "Bad Request: USER_ID_INVALID", "User ID is invalid")
+	ErrCantForward            = NewError(400, "Bad Request: message can't be forwarded")
+	ErrCantParseEntities      = NewError(400, "Bad Request: can't parse entities")
+	ErrChannelsTooMuch        = NewError(400, "Bad Request: CHANNELS_TOO_MUCH", "The chat has too many channels")
+	ErrChannelsTooMuchUser    = NewError(400, "Bad Request: USER_CHANNELS_TOO_MUCH", "The user has too many channels")
+	ErrChatAdminRequired      = NewError(400, "Bad Request: CHAT_ADMIN_REQUIRED", "Chat admin rights are required")
+	ErrFileTooBig             = NewError(400, "Bad Request: file is too big")
+	ErrHideRequesterMissing   = NewError(400, "Bad Request: HIDE_REQUESTER_MISSING", "The join request was missing or expired")
+	ErrInlineKeyboardExpected = NewError(400, "Bad Request: inline keyboard expected")
+	ErrNoRightsToPin          = NewError(400, "Bad Request: not enough rights to manage pinned messages in the chat")
+	ErrNoRightsToSendText     = NewError(400, "Bad Request: not enough rights to send text messages to the chat")
+	ErrNotFoundToCopy         = NewError(400, "Bad Request: message to copy not found")
+	ErrNotFoundToEdit         = NewError(400, "Bad Request: message to edit not found")
+	ErrNotFoundToPin          = NewError(400, "Bad Request: message to pin not found")
+	ErrOnlySupergroups        = NewError(400, "Bad Request: method is available only for supergroups")
+	ErrPhotoDimensions        = NewError(400, "Bad Request: PHOTO_INVALID_DIMENSIONS", "Photo dimensions are invalid")
+	ErrResultIDDuplicate      = NewError(400, "Bad Request: RESULT_ID_DUPLICATE", "Result IDs are duplicated")
+	ErrStickerDimensions      = NewError(400, "Bad Request: STICKER_PNG_DIMENSIONS", "Sticker dimensions are invalid")
+	ErrStickersTooMuch        = NewError(400, "Bad Request: STICKERS_TOO_MUCH", "The sticker set has too many stickers")
+	ErrThreadNotFound         = NewError(400, "Bad Request: message thread not found")
+	ErrTooLongCaption         = NewError(400, "Bad Request: message caption is too long")
+	ErrTopicNotModified       = NewError(400, "Bad Request: TOPIC_NOT_MODIFIED", "Topic is not modified")
+	ErrUserAlreadyParticipant = NewError(400, "Bad Request: USER_ALREADY_PARTICIPANT", "User is already a participant")
+	ErrUserNotFound           = NewError(400, "Bad Request: user not found")
+	ErrWebhookPort            = NewError(400, "Bad Request: bad webhook: Webhook can be set up only on ports 80, 88, 443 or 8443")
 )
 
 // Forbidden errors
@@ -129,11 +193,17 @@ var (
 	ErrKickedFromChannel    = NewError(403, "Forbidden: bot was kicked from the channel chat")
 	ErrNotStartedByUser     = NewError(403, "Forbidden: bot can't initiate conversation with a user")
 	ErrUserIsDeactivated    = NewError(403, "Forbidden: user is deactivated")
+	ErrNotChannelMember     = NewError(403, "Forbidden: bot is not a member of the channel chat")
+	ErrNotSuperGroupMember  = NewError(403, "Forbidden: bot is not a member of the supergroup chat")
+	ErrGroupDeleted         = NewError(403, "Forbidden: the group chat was deleted")
+	ErrBotToBot             = NewError(403, "Forbidden: bot can't send messages to bots")
 )
 
 // Err returns Error instance by given description.
+// The description is matched against the patterns of known
+// errors, so slightly reworded descriptions are recognized too.
 func Err(s string) error {
-	if r, ok := ErrMap[fnv.Hash32(s)]; ok {
+	if r := lookupError(0, s); r != nil {
 		return r
 	}
 	return nil
@@ -1,57 +1,147 @@
 package telebot
 
-var ErrMap map[uint32]*Error = map[uint32]*Error{
-	hash32(ErrTooLarge):     ErrTooLarge,
-	hash32(ErrUnauthorized): ErrUnauthorized,
-	hash32(ErrNotFound):     ErrNotFound,
-	hash32(ErrInternal):     ErrInternal,
-
-	hash32(ErrBadButtonData):          ErrBadButtonData,
-	hash32(ErrBadPollOptions):         ErrBadPollOptions,
-	hash32(ErrBadURLContent):          ErrBadURLContent,
-	hash32(ErrCantEditMessage):        ErrCantEditMessage,
-	hash32(ErrCantRemoveOwner):        ErrCantRemoveOwner,
-	hash32(ErrCantUploadFile):         ErrCantUploadFile,
-	hash32(ErrCantUseMediaInAlbum):    ErrCantUseMediaInAlbum,
-	hash32(ErrChatAboutNotModified):   ErrChatAboutNotModified,
-	hash32(ErrChatNotFound):           ErrChatNotFound,
-	hash32(ErrEmptyChatID):            ErrEmptyChatID,
-	hash32(ErrEmptyMessage):           ErrEmptyMessage,
-	hash32(ErrEmptyText):              ErrEmptyText,
-	hash32(ErrFailedImageProcess):     ErrFailedImageProcess,
-	hash32(ErrGroupMigrated):          ErrGroupMigrated,
-	hash32(ErrMessageNotModified):     ErrMessageNotModified,
-	hash32(ErrNoRightsToDelete):       ErrNoRightsToDelete,
-	hash32(ErrNoRightsToRestrict):     ErrNoRightsToRestrict,
-	hash32(ErrNoRightsToSend):         ErrNoRightsToSend,
-	hash32(ErrNoRightsToSendGifs):     ErrNoRightsToSendGifs,
-	hash32(ErrNoRightsToSendPhoto):    ErrNoRightsToSendPhoto,
-	hash32(ErrNoRightsToSendStickers): ErrNoRightsToSendStickers,
-	hash32(ErrNotFoundToDelete):       ErrNotFoundToDelete,
-	hash32(ErrNotFoundToForward):      ErrNotFoundToForward,
-	hash32(ErrNotFoundToReply):        ErrNotFoundToReply,
-	hash32(ErrQueryTooOld):            ErrQueryTooOld,
-	hash32(ErrSameMessageContent):     ErrSameMessageContent,
-	hash32(ErrStickerEmojisInvalid):   ErrStickerEmojisInvalid,
-	hash32(ErrStickerSetInvalid):      ErrStickerSetInvalid,
-	hash32(ErrStickerSetInvalidName):  ErrStickerSetInvalidName,
-	hash32(ErrStickerSetNameOccupied): ErrStickerSetNameOccupied,
-	hash32(ErrTooLongMarkup):          ErrTooLongMarkup,
-	hash32(ErrTooLongMessage):         ErrTooLongMessage,
-	hash32(ErrUserIsAdmin):            ErrUserIsAdmin,
-	hash32(ErrWrongFileID):            ErrWrongFileID,
-	hash32(ErrWrongFileIDCharacter):   ErrWrongFileIDCharacter,
-	hash32(ErrWrongFileIDLength):      ErrWrongFileIDLength,
-	hash32(ErrWrongFileIDPadding):     ErrWrongFileIDPadding,
-	hash32(ErrWrongFileIDSymbol):      ErrWrongFileIDSymbol,
-	hash32(ErrWrongTypeOfContent):     ErrWrongTypeOfContent,
-	hash32(ErrWrongURL):               ErrWrongURL,
-	hash32(ErrForwardMessage):         ErrForwardMessage,
-
-	hash32(ErrBlockedByUser):        ErrBlockedByUser,
-	hash32(ErrKickedFromGroup):      ErrKickedFromGroup,
-	hash32(ErrKickedFromSuperGroup): ErrKickedFromSuperGroup,
-	hash32(ErrKickedFromChannel):    ErrKickedFromChannel,
-	hash32(ErrNotStartedByUser):     ErrNotStartedByUser,
-	hash32(ErrUserIsDeactivated):    ErrUserIsDeactivated,
+import (
+	"strings"
+
+	"github.com/grafana/regexp"
+)
+
+// errorPattern binds a known API error to the expression
+// matching all the descriptions Telegram uses for it.
+// The error code of the known error must match as well.
+type errorPattern struct {
+	err *Error
+	rx  *regexp.Regexp
+}
+
+func pattern(err *Error, expr string) errorPattern {
+	return errorPattern{err: err, rx: regexp.MustCompile("(?i)" + expr)}
+}
+
+// errorCatalog lists known API errors. The order matters:
+// more specific patterns must precede the general ones.
+var errorCatalog = []errorPattern{
+	pattern(ErrTooLarge, `request entity too large`),
+	pattern(ErrUnauthorized, `^unauthorized`),
+	pattern(ErrNotFound, `^not found$`),
+	pattern(ErrInternal, `internal server error`),
+
+	pattern(ErrTerminatedByOther, `terminated by other getupdates request`),
+	pattern(ErrWebhookActive, `can't use getupdates method while webhook is active`),
+	pattern(ErrTooManyRequests, `^too many requests`),
+
+	pattern(ErrBadButtonData, `button_data_invalid`),
+	pattern(ErrBadPollOptions, `expected an array of string as options`),
+	pattern(ErrBadURLContent, `failed to get http url content`),
+	pattern(ErrCantEditMessage, `message can't be edited`),
+	pattern(ErrCantRemoveOwner, `can't remove chat owner`),
+	pattern(ErrCantUploadFile, `can't upload file by url`),
+	pattern(ErrCantUseMediaInAlbum, `can't use the media of the specified type in the album`),
+	pattern(ErrChatAboutNotModified, `chat description is not modified`),
+	pattern(ErrChatNotFound, `chat not found`),
+	pattern(ErrEmptyChatID, `chat_id is empty`),
+	pattern(ErrEmptyMessage, `message must be non-empty`),
+	pattern(ErrEmptyText, `text (is |must be non-)empty`),
+	pattern(ErrFailedImageProcess, `image_process_failed`),
+	pattern(ErrGroupMigrated, `group chat was upgraded to a supergroup`),
+	pattern(ErrSameMessageContent, `message is not modified: specified new message content`),
+	pattern(ErrMessageNotModified, `message is not modified`),
+	pattern(ErrNoRightsToDelete, `message can't be deleted`),
+	pattern(ErrNoRightsToRestrict, `not enough rights to restrict/unrestrict chat member`),
+	pattern(ErrNoRightsToSend, `have no rights to send a message`),
+	pattern(ErrNoRightsToSendGifs, `chat_send_gifs_forbidden`),
+	pattern(ErrNoRightsToSendPhoto, `not enough rights to send photos`),
+	pattern(ErrNoRightsToSendStickers, `not enough rights to send stickers`),
+	pattern(ErrNoRightsToSendText, `not enough rights to send text messages`),
+	pattern(ErrNoRightsToPin, `not enough rights to (manage )?pin`),
+	pattern(ErrNotFoundToDelete, `message to delete not found`),
+	pattern(ErrNotFoundToForward, `message to forward not found`),
+	pattern(ErrNotFoundToReply, `(reply message|message to (be )?repl(y|ied)) not found`),
+	pattern(ErrNotFoundToCopy, `message to copy not found`),
+	pattern(ErrNotFoundToEdit, `message to edit not found`),
+	pattern(ErrNotFoundToPin, `message to pin not found`),
+	pattern(ErrQueryTooOld, `query is too old`),
+	pattern(ErrStickerEmojisInvalid, `invalid sticker emojis`),
+	pattern(ErrStickerSetInvalid, `stickerset_invalid`),
+	pattern(ErrStickerSetInvalidName, `invalid sticker set name`),
+	pattern(ErrStickerSetNameOccupied, `sticker set name is already occupied`),
+	pattern(ErrTooLongMarkup, `reply markup is too long`),
+	pattern(ErrTooLongCaption, `caption is too long`),
+	pattern(ErrTooLongMessage, `message is too long`),
+	pattern(ErrUserIsAdmin, `user is an administrator of the chat`),
+	pattern(ErrWrongFileIDCharacter, `wrong remote file id specified: wrong character`),
+	pattern(ErrWrongFileIDLength, `wrong remote file id specified: wrong string length`),
+	pattern(ErrWrongFileIDPadding, `wrong remote file id specified: wrong padding`),
+	pattern(ErrWrongFileIDSymbol, `wrong remote file id specified: .*wrong last symbol`),
+	pattern(ErrWrongFileID, `wrong file identifier/http url specified`),
+	pattern(ErrWrongTypeOfContent, `wrong type of the web page content`),
+	pattern(ErrWrongURL, `wrong http url specified`),
+	pattern(ErrForwardMessage, `administrators of the chat restricted message forwarding`),
+	pattern(ErrBadMessageID, `message_id_invalid`),
+	pattern(ErrBadPeerID, `peer_id_invalid`),
+	pattern(ErrBadUserID, `user_id_invalid`),
+	pattern(ErrCantForward, `message can't be forwarded`),
+	pattern(ErrCantParseEntities, `can't parse entities`),
+	pattern(ErrChannelsTooMuchUser, `user_channels_too_much`),
+	pattern(ErrChannelsTooMuch, `channels_too_much`),
+	pattern(ErrChatAdminRequired, `chat_admin_required`),
+	pattern(ErrFileTooBig, `file is too big`),
+	pattern(ErrHideRequesterMissing, `hide_requester_missing`),
+	pattern(ErrInlineKeyboardExpected, `inline keyboard expected`),
+	pattern(ErrOnlySupergroups, `method is available only for supergroups`),
+	pattern(ErrPhotoDimensions, `photo_invalid_dimensions`),
+	pattern(ErrResultIDDuplicate, `result_id_duplicate`),
+	pattern(ErrStickerDimensions, `sticker_png_dimensions`),
+	pattern(ErrStickersTooMuch, `stickers_too_much`),
+	pattern(ErrThreadNotFound, `message thread not found`),
+	pattern(ErrTopicNotModified, `topic_not_modified`),
+	pattern(ErrUserAlreadyParticipant, `user_already_participant`),
+	pattern(ErrUserNotFound, `user not found`),
+	pattern(ErrWebhookPort, `webhook can be set up only on ports`),
+
+	pattern(ErrBlockedByUser, `bot was blocked by the user`),
+	pattern(ErrKickedFromGroup, `bot was kicked from the group chat`),
+	pattern(ErrKickedFromSuperGroup, `bot was kicked from the supergroup chat`),
+	pattern(ErrKickedFromChannel, `bot was kicked from the channel chat`),
+	pattern(ErrNotStartedByUser, `bot can't initiate conversation with a user`),
+	pattern(ErrUserIsDeactivated, `user is deactivated`),
+	pattern(ErrNotChannelMember, `bot is not a member of the channel chat`),
+	pattern(ErrNotSuperGroupMember, `bot is not a member of the supergroup chat`),
+	pattern(ErrGroupDeleted, `the group chat was deleted`),
+	pattern(ErrBotToBot, `bot can't send messages to bots`),
+}
+
+// ErrMap indexes known errors by the hash of their exact description.
+//
+// Deprecated: exact descriptions break whenever Telegram rewords them.
+// Use Err or errors.Is instead.
+var ErrMap = func() map[uint32]*Error {
+	m := make(map[uint32]*Error, len(errorCatalog))
+	for _, p := range errorCatalog {
+		m[hash32(p.err)] = p.err
+	}
+	return m
+}()
+
+// lookupError returns the known error matching the code and the
+// description, or nil. Zero code matches the description only.
+func lookupError(code int, description string) *Error {
+	for _, p := range errorCatalog {
+		if code != 0 && p.err.Code != code {
+			continue
+		}
+		if p.rx.MatchString(description) {
+			return p.err
+		}
+	}
+	return nil
+}
+
+// canonicalDescription returns the description of the known error
+// matching the given one. Unknown descriptions are only normalized.
+func canonicalDescription(code int, description string) string {
+	if err := lookupError(code, description); err != nil {
+		return err.Description
+	}
+	return strings.ToLower(strings.TrimSpace(description))
 }
@@ -0,0 +1,63 @@
+package telebot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/3JoB/ulib/pool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErr(t *testing.T) {
+	for _, p := range errorCatalog {
+		assert.Same(t, p.err, Err(p.err.Description), p.err.Description)
+	}
+
+	assert.Equal(t, ErrNotFoundToReply, Err("Bad Request: message to be replied not found"))
+	assert.Equal(t, ErrEmptyText, Err("Bad Request: message text is empty"))
+	assert.Equal(t, ErrChannelsTooMuchUser, Err("Bad Request: USER_CHANNELS_TOO_MUCH"))
+	assert.Nil(t, Err("Bad Request: something new"))
+}
+
+func TestErrorIs(t *testing.T) {
+	assert.ErrorIs(t, NewError(400, "Bad Request: CHAT NOT FOUND"), ErrChatNotFound)
+	assert.ErrorIs(t, NewError(400, "bad request: custom"), NewError(400, "Bad Request: custom"))
+	assert.NotErrorIs(t, NewError(403, "Bad Request: chat not found"), ErrChatNotFound)
+	assert.NotErrorIs(t, NewError(400, "Bad Request: chat not found"), errors.New("chat not found"))
+
+	flood := FloodError{err: NewError(429, "Too Many Requests: retry after 5"), RetryAfter: 5}
+	assert.ErrorIs(t, flood, ErrTooManyRequests)
+
+	group := GroupError{err: ErrGroupMigrated, MigratedTo: -100}
+	assert.ErrorIs(t, group, ErrGroupMigrated)
+}
+
+func TestExtractParameters(t *testing.T) {
+	buf := pool.NewBuffer()
+	buf.WriteString(`{
+		"ok": false,
+		"error_code": 400,
+		"description": "Bad Request: the group was upgraded",
+		"parameters": {"migrate_to_chat_id": -100123}
+	}`)
+
+	var ge GroupError
+	err := extractOk(buf)
+	assert.ErrorAs(t, err, &ge)
+	assert.Equal(t, int64(-100123), ge.MigratedTo)
+
+	buf = pool.NewBuffer()
+	buf.WriteString(`{"ok": false, "error_code": 400, "description": "Bad Request: unknown"}`)
+	assert.Equal(t, NewError(400, "Bad Request: unknown"), extractOk(buf))
+
+	// The code of the known error must match too.
+	buf = pool.NewBuffer()
+	buf.WriteString(`{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"}`)
+	assert.Same(t, ErrChatNotFound, extractOk(buf))
+
+	buf = pool.NewBuffer()
+	buf.WriteString(`{"ok": false, "error_code": 403, "description": "Forbidden: chat not found"}`)
+	err = extractOk(buf)
+	assert.Equal(t, NewError(403, "Forbidden: chat not found"), err)
+	assert.NotErrorIs(t, err, ErrChatNotFound)
+}
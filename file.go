@@ -25,6 +25,12 @@ type File struct {
 
 	// FileReader is used for file backed with io.Reader.
 	FileReader io.Reader `json:"-"`
+
+	// OnProgress is called while the file is uploaded with the number of
+	// bytes sent and the total size, which is -1 if it's unknown. The size
+	// of a reader is known if FileSize is set, or if the reader has Len or
+	// Seek methods. It's called from the goroutine writing the request.
+	OnProgress func(sent, total int64) `json:"-"`
 }
 
 // FromDisk constructs a new local (on-disk) file object.
@@ -83,3 +89,50 @@ func (f *File) OnDisk() bool {
 	_, err := os.Stat(f.FileLocal)
 	return err == nil
 }
+
+// size returns the size of the file to upload, or -1 if it's unknown.
+func (f *File) size(r io.Reader) int64 {
+	if f.FileSize > 0 {
+		return f.FileSize
+	}
+
+	switch r := r.(type) {
+	case *os.File:
+		if stat, err := r.Stat(); err == nil && stat.Mode().IsRegular() {
+			return stat.Size()
+		}
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case io.Seeker:
+		cur, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := r.Seek(cur, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - cur
+	}
+	return -1
+}
+
+// progressReader reports the progress of reading the file.
+type progressReader struct {
+	r     io.Reader
+	sent  int64
+	total int64
+	fn    func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.fn(p.sent, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,203 @@
+package telebot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/3JoB/ulib/litefmt"
+	"github.com/cornelk/hashmap"
+)
+
+// FileCache remembers the file IDs of uploaded files, so sending the
+// same file again sends its file ID instead of uploading it. Keys are
+// built from the bot ID, the media type and the file itself: the path,
+// size and modification time of files on disk, or the content hash of
+// readers implementing io.Seeker. Other readers are always uploaded.
+type FileCache interface {
+	// Get returns the file ID by the key.
+	Get(key string) (fileID string, ok bool)
+
+	// Set stores the file ID by the key.
+	Set(key, fileID string) error
+}
+
+// NewFileMemoryCache returns a FileCache, which keeps file IDs in memory.
+func NewFileMemoryCache() FileCache {
+	return &fileMemoryCache{m: hashmap.New[string, string]()}
+}
+
+type fileMemoryCache struct {
+	m *hashmap.Map[string, string]
+}
+
+func (c *fileMemoryCache) Get(key string) (string, bool) {
+	return c.m.Get(key)
+}
+
+func (c *fileMemoryCache) Set(key, fileID string) error {
+	c.m.Set(key, fileID)
+	return nil
+}
+
+// NewFileDiskCache returns a FileCache, which keeps file IDs in the
+// JSON file at the path, so they survive restarts. The file is read
+// once and rewritten on every new file ID.
+func NewFileDiskCache(path string) (FileCache, error) {
+	c := &fileDiskCache{path: path, m: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if err := defaultJson.Unmarshal(data, &c.m); err != nil {
+		return nil, wrapError(err)
+	}
+	return c, nil
+}
+
+type fileDiskCache struct {
+	path string
+	mu   sync.RWMutex
+	m    map[string]string
+}
+
+func (c *fileDiskCache) Get(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	id, ok := c.m[key]
+	return id, ok
+}
+
+func (c *fileDiskCache) Set(key, fileID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.m[key] == fileID {
+		return nil
+	}
+	c.m[key] = fileID
+
+	data, err := defaultJson.Marshal(c.m)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	tmp := litefmt.PSprint(c.path, ".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return wrapError(err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// fileCacheKey returns the cache key of the file to upload,
+// or an empty string if the file can't be cached.
+func (b *Bot) fileCacheKey(kind string, f *File) string {
+	if b.fileCache == nil || f.InCloud() || f.FileURL != "" {
+		return ""
+	}
+
+	var id string
+	switch rs, seeker := f.FileReader.(io.ReadSeeker); {
+	case f.FileReader == nil && f.OnDisk():
+		stat, err := os.Stat(f.FileLocal)
+		if err != nil {
+			return ""
+		}
+		path, err := filepath.Abs(f.FileLocal)
+		if err != nil {
+			return ""
+		}
+		id = litefmt.PSprint(
+			"path:", path,
+			":", strconv.FormatInt(stat.Size(), 10),
+			":", strconv.FormatInt(stat.ModTime().UnixNano(), 10),
+		)
+	case seeker:
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return ""
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, rs)
+		if _, serr := rs.Seek(start, io.SeekStart); err != nil || serr != nil {
+			return ""
+		}
+		id = litefmt.PSprint("sha256:", hex.EncodeToString(h.Sum(nil)))
+	default:
+		return ""
+	}
+
+	var botID int64
+	if b.Me != nil {
+		botID = b.Me.ID
+	}
+	return litefmt.PSprint(strconv.FormatInt(botID, 10), ":", kind, ":", id)
+}
+
+// cachedFileID returns the cached file ID by the key.
+func (b *Bot) cachedFileID(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	return b.fileCache.Get(key)
+}
+
+// cacheFileID stores the file ID, if the key isn't empty.
+func (b *Bot) cacheFileID(key, fileID string) {
+	if key == "" || fileID == "" {
+		return
+	}
+	if err := b.fileCache.Set(key, fileID); err != nil {
+		b.debug(err)
+	}
+}
+
+// isFileRejected reports whether the request failed
+// because of an invalid or outdated file ID.
+func isFileRejected(err error) bool {
+	for _, rejected := range []error{
+		ErrWrongFileID,
+		ErrWrongFileIDCharacter,
+		ErrWrongFileIDLength,
+		ErrWrongFileIDPadding,
+		ErrWrongFileIDSymbol,
+	} {
+		if errors.Is(err, rejected) {
+			return true
+		}
+	}
+	return false
+}
+
+// readerOffsets returns the current offsets of the readers the album
+// uploads, and reports whether all of them can be seeked back.
+func readerOffsets(a Album) (map[int]int64, bool) {
+	offsets := make(map[int]int64)
+	for i, x := range a {
+		f := x.MediaFile()
+		if f.FileReader == nil || f.InCloud() || f.FileURL != "" || f.OnDisk() {
+			continue
+		}
+		s, ok := f.FileReader.(io.Seeker)
+		if !ok {
+			return nil, false
+		}
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false
+		}
+		offsets[i] = offset
+	}
+	return offsets, true
+}
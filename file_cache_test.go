@@ -0,0 +1,138 @@
+package telebot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCache(t *testing.T) {
+	var uploads, sent, contents []string
+	cache := NewFileMemoryCache()
+	b := newFakeBot(t, Settings{FileCache: cache}, func(w http.ResponseWriter, r *http.Request) {
+		method := fakeMethod(r)
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			if strings.Contains(r.FormValue("media"), "stale") {
+				w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`))
+				return
+			}
+			uploads = append(uploads, method)
+			for _, fh := range r.MultipartForm.File {
+				f, err := fh[0].Open()
+				require.NoError(t, err)
+				data, _ := io.ReadAll(f)
+				contents = append(contents, string(data))
+			}
+			id := "id" + strconv.Itoa(len(uploads))
+			if method == "sendMediaGroup" {
+				w.Write([]byte(`{"ok":true,"result":[{"photo":[{"file_id":"` + id + `a"}]},{"photo":[{"file_id":"` + id + `b"}]}]}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1,"document":{"file_id":"` + id + `"}}}`))
+			return
+		}
+
+		var params map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		if method == "sendMediaGroup" {
+			var media []InputMedia
+			require.NoError(t, json.Unmarshal([]byte(params["media"].(string)), &media))
+			for _, m := range media {
+				sent = append(sent, m.Media)
+			}
+			w.Write([]byte(`{"ok":true,"result":[{"photo":[{"file_id":"x"}]},{"photo":[{"file_id":"y"}]}]}`))
+			return
+		}
+
+		id := params["document"].(string)
+		sent = append(sent, id)
+		if id == "stale" {
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1,"document":{"file_id":"` + id + `"}}}`))
+	})
+
+	to := &Chat{ID: 1}
+
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	require.NoError(t, os.WriteFile(path, []byte("document"), 0o644))
+
+	for i := 0; i < 2; i++ {
+		_, err := b.Send(to, &Document{File: FromDisk(path)})
+		require.NoError(t, err)
+		_, err = b.Send(to, &Document{File: FromReader(bytes.NewReader([]byte("reader")))})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"sendDocument", "sendDocument"}, uploads)
+	assert.Equal(t, []string{"id1", "id2"}, sent)
+
+	// An outdated file ID is replaced by the uploaded file.
+	key := b.fileCacheKey("document", &File{FileLocal: path})
+	require.NoError(t, cache.Set(key, "stale"))
+	doc := &Document{File: FromDisk(path)}
+	_, err := b.Send(to, doc)
+	require.NoError(t, err)
+	assert.Equal(t, "id3", doc.FileID)
+	id, _ := cache.Get(key)
+	assert.Equal(t, "id3", id)
+
+	uploads, sent = nil, nil
+	album := func() Album {
+		return Album{
+			&Photo{File: FromReader(bytes.NewReader([]byte("a")))},
+			&Photo{File: FromReader(bytes.NewReader([]byte("b")))},
+		}
+	}
+	_, err = b.SendAlbum(to, album())
+	require.NoError(t, err)
+	_, err = b.SendAlbum(to, album())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sendMediaGroup"}, uploads)
+	assert.Equal(t, []string{"id1a", "id1b"}, sent)
+
+	// The readers drained by the attempt with an outdated
+	// file ID are uploaded again from the start.
+	uploads, contents = nil, nil
+	stale := album()
+	require.NoError(t, cache.Set(b.fileCacheKey("photo", stale[0].MediaFile()), "stale"))
+	stale[1] = &Photo{File: FromReader(bytes.NewReader([]byte("c")))}
+	_, err = b.SendAlbum(to, stale)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sendMediaGroup"}, uploads)
+	assert.ElementsMatch(t, []string{"a", "c"}, contents)
+	id, _ = cache.Get(b.fileCacheKey("photo", &File{FileReader: bytes.NewReader([]byte("c"))}))
+	assert.Equal(t, "id1b", id)
+
+	assert.False(t, isFileRejected(NewError(400, "Bad Request: file is too big")))
+
+	// Readers without Seek can't be hashed.
+	assert.Empty(t, b.fileCacheKey("document", &File{FileReader: io.MultiReader(strings.NewReader("x"))}))
+}
+
+func TestFileDiskCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.json")
+
+	cache, err := NewFileDiskCache(path)
+	require.NoError(t, err)
+	_, ok := cache.Get("key")
+	assert.False(t, ok)
+	require.NoError(t, cache.Set("key", "id"))
+
+	cache, err = NewFileDiskCache(path)
+	require.NoError(t, err)
+	id, ok := cache.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "id", id)
+}
@@ -1,10 +1,19 @@
 package telebot
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3JoB/telebot/v2/pkg/net"
 )
 
 func TestFile(t *testing.T) {
@@ -22,3 +31,62 @@ func TestFile(t *testing.T) {
 	assert.Equal(t, g.FileLocal, f.FileLocal)
 	assert.Equal(t, f.FileURL, g.FileURL)
 }
+
+func TestBotFile(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 100_000)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botTOKEN/getFile":
+			var params map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+			w.Write([]byte(`{"ok":true,"result":{"file_id":"1","file_path":"` + params["file_id"] + `"}}`))
+		case "/file/botTOKEN/ranged":
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+		case "/file/botTOKEN/whole":
+			w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+
+	for name, client := range map[string]net.NetFrame{
+		"fasthttp": net.NewFastHTTPClient(),
+		"net/http": net.NewHTTPClient(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			b := newFakeBot(t, Settings{Token: "TOKEN", Client: client}, handler)
+
+			read := func(r io.ReadCloser, err error) []byte {
+				require.NoError(t, err)
+				defer r.Close()
+				got, err := io.ReadAll(r)
+				require.NoError(t, err)
+				return got
+			}
+
+			for _, path := range []string{"ranged", "whole"} {
+				file := &File{FileID: path}
+				assert.Equal(t, data, read(b.File(file)))
+				assert.Equal(t, path, file.FilePath)
+				assert.Equal(t, data[1000:1500], read(b.FileRange(file, 1000, 500)), path)
+				assert.Equal(t, data[999_990:], read(b.FileRange(file, 999_990, 0)), path)
+			}
+
+			dir := t.TempDir()
+			dst := filepath.Join(dir, "file.bin")
+			file := &File{FileID: "ranged"}
+			require.NoError(t, b.Download(file, dst))
+			assert.Equal(t, dst, file.FileLocal)
+			got, err := os.ReadFile(dst)
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+
+			assert.Error(t, b.Download(&File{FileID: "missing"}, filepath.Join(dir, "missing.bin")))
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, "file.bin", entries[0].Name())
+		})
+	}
+}
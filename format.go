@@ -0,0 +1,435 @@
+package telebot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Text is a formatted text: plain text with a list of entities.
+// Build it with Format and the styling functions (Bold, Italic,
+// Link, ...), which can be nested:
+//
+//	t := tele.Format("Hello, ", tele.Bold("dear ", tele.Italic(user.FirstName)), "!")
+//	b.Send(to, t)
+//
+// Text is Sendable: it is sent as the plain text with entities,
+// so nothing has to be escaped. HTML and MarkdownV2 return it
+// rendered with the parse mode, escaping everything needed.
+type Text struct {
+	text     strings.Builder
+	length   int
+	entities Entities
+}
+
+// Format concatenates its arguments into a formatted text.
+// Strings are added as is, formatted texts keep their entities,
+// any other value is formatted with fmt.Sprint.
+func Format(a ...any) *Text {
+	return new(Text).Append(a...)
+}
+
+// Append adds the arguments to the end of the text the same way
+// as Format does and returns the text itself.
+func (t *Text) Append(a ...any) *Text {
+	for _, v := range a {
+		switch v := v.(type) {
+		case string:
+			t.appendString(v)
+		case *Text:
+			for _, e := range v.entities {
+				e.Offset += t.length
+				t.entities = append(t.entities, e)
+			}
+			t.appendString(v.String())
+		default:
+			t.appendString(fmt.Sprint(v))
+		}
+	}
+	return t
+}
+
+func (t *Text) appendString(s string) {
+	t.text.WriteString(s)
+	t.length += utf16Len(s)
+}
+
+// String returns the plain text without any formatting.
+func (t *Text) String() string {
+	return t.text.String()
+}
+
+// Len returns the length of the text in UTF-16 code units,
+// the same way Telegram counts the length of messages.
+func (t *Text) Len() int {
+	return t.length
+}
+
+// Entities returns the entities of the text.
+func (t *Text) Entities() Entities {
+	return t.entities
+}
+
+// HTML returns the text rendered for ModeHTML.
+func (t *Text) HTML() string {
+	return renderEntities(t.String(), t.entities, htmlRenderer{})
+}
+
+// MarkdownV2 returns the text rendered for ModeMarkdownV2.
+func (t *Text) MarkdownV2() string {
+	return renderEntities(t.String(), t.entities, &markdownV2Renderer{})
+}
+
+// Send delivers the text with its entities through bot b to recipient.
+// The parse mode of the bot or options is ignored.
+func (t *Text) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
+	params := map[string]any{
+		"chat_id": to.Recipient(),
+		"text":    t.String(),
+	}
+	b.embedSendOptions(params, opt)
+	delete(params, "parse_mode")
+
+	if len(t.entities) > 0 {
+		entities, _ := b.json.Marshal(t.entities)
+		params["entities"] = string(entities)
+	}
+
+	data, err := b.Raw("sendMessage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractMessage(data)
+}
+
+func styled(e MessageEntity, a []any) *Text {
+	t := Format(a...)
+	if t.length == 0 {
+		return t
+	}
+	e.Length = t.length
+	t.entities = append(Entities{e}, t.entities...)
+	return t
+}
+
+// Bold returns the arguments formatted as bold text.
+func Bold(a ...any) *Text {
+	return styled(MessageEntity{Type: EntityBold}, a)
+}
+
+// Italic returns the arguments formatted as italic text.
+func Italic(a ...any) *Text {
+	return styled(MessageEntity{Type: EntityItalic}, a)
+}
+
+// Underline returns the arguments formatted as underlined text.
+func Underline(a ...any) *Text {
+	return styled(MessageEntity{Type: EntityUnderline}, a)
+}
+
+// Strikethrough returns the arguments formatted as strikethrough text.
+func Strikethrough(a ...any) *Text {
+	return styled(MessageEntity{Type: EntityStrikethrough}, a)
+}
+
+// Spoiler returns the arguments hidden under a spoiler.
+func Spoiler(a ...any) *Text {
+	return styled(MessageEntity{Type: EntitySpoiler}, a)
+}
+
+// Code returns the inline fixed-width code.
+func Code(code string) *Text {
+	return styled(MessageEntity{Type: EntityCode}, []any{code})
+}
+
+// Pre returns the pre-formatted code block.
+// The language is optional and may be empty.
+func Pre(language, code string) *Text {
+	return styled(MessageEntity{Type: EntityCodeBlock, Language: language}, []any{code})
+}
+
+// Link returns the arguments as a link to the URL.
+// If no arguments given, the URL itself is used as the text.
+func Link(url string, a ...any) *Text {
+	if len(a) == 0 {
+		a = []any{url}
+	}
+	return styled(MessageEntity{Type: EntityTextLink, URL: url}, a)
+}
+
+// Mention returns the arguments as a mention of the user, which works
+// even for users without username. If no arguments given, the full
+// name of the user is used as the text.
+func Mention(user *User, a ...any) *Text {
+	if len(a) == 0 {
+		name := user.FirstName
+		if user.LastName != "" {
+			name += " " + user.LastName
+		}
+		a = []any{name}
+	}
+	return styled(MessageEntity{Type: EntityTMention, User: user}, a)
+}
+
+// CustomEmoji returns the custom emoji with the given ID.
+// The emoji is the regular one, shown where custom ones
+// are not supported.
+func CustomEmoji(id, emoji string) *Text {
+	return styled(MessageEntity{Type: EntityCustomEmoji, CustomEmoji: id}, []any{emoji})
+}
+
+func utf16Len(s string) (n int) {
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// entityRenderer renders entities in some parse mode.
+type entityRenderer interface {
+	escape(s string, code bool) string
+	open(e *MessageEntity) string
+	close(e *MessageEntity) string
+}
+
+// renderEntities renders the text with entities, escaping all the rest.
+// Entities, which are not formatting, like hashtags or URLs,
+// are rendered as the plain text.
+func renderEntities(text string, entities Entities, r entityRenderer) string {
+	if len(entities) == 0 {
+		return r.escape(text, false)
+	}
+
+	units := utf16.Encode([]rune(text))
+
+	type span struct {
+		*MessageEntity
+		end int
+	}
+
+	spans := make([]span, 0, len(entities))
+	for i := range entities {
+		e := &entities[i]
+		if (htmlRenderer{}).open(e) == "" {
+			continue
+		}
+		start, end := max(e.Offset, 0), min(e.Offset+e.Length, len(units))
+		if start >= end {
+			continue
+		}
+		spans = append(spans, span{e, end})
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Offset != spans[j].Offset {
+			return spans[i].Offset < spans[j].Offset
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var (
+		sb    strings.Builder
+		stack []span
+		next  int
+	)
+
+	for pos := 0; ; {
+		// Close the entities ending here. If the entity isn't the
+		// innermost one, the inner ones are closed and reopened.
+		i := 0
+		for i < len(stack) && stack[i].end > pos {
+			i++
+		}
+		if i < len(stack) {
+			for j := len(stack) - 1; j >= i; j-- {
+				sb.WriteString(r.close(stack[j].MessageEntity))
+			}
+			inner := append([]span(nil), stack[i+1:]...)
+			stack = stack[:i]
+			for _, s := range inner {
+				if s.end > pos {
+					sb.WriteString(r.open(s.MessageEntity))
+					stack = append(stack, s)
+				}
+			}
+		}
+
+		for next < len(spans) && max(spans[next].Offset, 0) == pos {
+			sb.WriteString(r.open(spans[next].MessageEntity))
+			stack = append(stack, spans[next])
+			next++
+		}
+
+		if pos == len(units) {
+			break
+		}
+
+		end := len(units)
+		if next < len(spans) {
+			end = min(end, spans[next].Offset)
+		}
+		code := false
+		for _, s := range stack {
+			end = min(end, s.end)
+			code = code || s.Type == EntityCode || s.Type == EntityCodeBlock
+		}
+
+		sb.WriteString(r.escape(string(utf16.Decode(units[pos:end])), code))
+		pos = end
+	}
+
+	return sb.String()
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+type htmlRenderer struct{}
+
+func (htmlRenderer) escape(s string, _ bool) string {
+	return htmlEscaper.Replace(s)
+}
+
+func (htmlRenderer) open(e *MessageEntity) string {
+	switch e.Type {
+	case EntityBold:
+		return "<b>"
+	case EntityItalic:
+		return "<i>"
+	case EntityUnderline:
+		return "<u>"
+	case EntityStrikethrough:
+		return "<s>"
+	case EntitySpoiler:
+		return "<tg-spoiler>"
+	case EntityCode:
+		return "<code>"
+	case EntityCodeBlock:
+		if e.Language != "" {
+			return `<pre><code class="language-` + htmlEscaper.Replace(e.Language) + `">`
+		}
+		return "<pre>"
+	case EntityTextLink:
+		return `<a href="` + htmlEscaper.Replace(e.URL) + `">`
+	case EntityTMention:
+		if e.User == nil {
+			return ""
+		}
+		return `<a href="tg://user?id=` + strconv.FormatInt(e.User.ID, 10) + `">`
+	case EntityCustomEmoji:
+		return `<tg-emoji emoji-id="` + htmlEscaper.Replace(e.CustomEmoji) + `">`
+	default:
+		return ""
+	}
+}
+
+func (htmlRenderer) close(e *MessageEntity) string {
+	switch e.Type {
+	case EntityBold:
+		return "</b>"
+	case EntityItalic:
+		return "</i>"
+	case EntityUnderline:
+		return "</u>"
+	case EntityStrikethrough:
+		return "</s>"
+	case EntitySpoiler:
+		return "</tg-spoiler>"
+	case EntityCode:
+		return "</code>"
+	case EntityCodeBlock:
+		if e.Language != "" {
+			return "</code></pre>"
+		}
+		return "</pre>"
+	case EntityTextLink, EntityTMention:
+		return "</a>"
+	case EntityCustomEmoji:
+		return "</tg-emoji>"
+	default:
+		return ""
+	}
+}
+
+var (
+	markdownV2Escaper = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+		"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+		"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	markdownV2CodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownV2URLEscaper  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+// markdownV2Renderer remembers whether the last written marker
+// was the italic one, so it can be separated from the adjacent
+// underline marker: https://core.telegram.org/bots/api#markdownv2-style.
+type markdownV2Renderer struct {
+	italic bool
+}
+
+func (r *markdownV2Renderer) escape(s string, code bool) string {
+	r.italic = false
+	if code {
+		return markdownV2CodeEscaper.Replace(s)
+	}
+	return markdownV2Escaper.Replace(s)
+}
+
+func (r *markdownV2Renderer) open(e *MessageEntity) string {
+	return r.marker(e, true)
+}
+
+func (r *markdownV2Renderer) close(e *MessageEntity) string {
+	return r.marker(e, false)
+}
+
+func (r *markdownV2Renderer) marker(e *MessageEntity, open bool) string {
+	italic := r.italic
+	r.italic = e.Type == EntityItalic
+
+	switch e.Type {
+	case EntityBold:
+		return "*"
+	case EntityItalic:
+		return "_"
+	case EntityUnderline:
+		if italic {
+			return "\r__"
+		}
+		return "__"
+	case EntityStrikethrough:
+		return "~"
+	case EntitySpoiler:
+		return "||"
+	case EntityCode:
+		return "`"
+	case EntityCodeBlock:
+		if open {
+			return "```" + markdownV2CodeEscaper.Replace(e.Language) + "\n"
+		}
+		return "\n```"
+	case EntityTextLink:
+		if open {
+			return "["
+		}
+		return "](" + markdownV2URLEscaper.Replace(e.URL) + ")"
+	case EntityTMention:
+		if open {
+			return "["
+		}
+		return "](tg://user?id=" + strconv.FormatInt(e.User.ID, 10) + ")"
+	case EntityCustomEmoji:
+		if open {
+			return "!["
+		}
+		return "](tg://emoji?id=" + markdownV2URLEscaper.Replace(e.CustomEmoji) + ")"
+	default:
+		return ""
+	}
+}
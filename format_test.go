@@ -0,0 +1,77 @@
+package telebot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	user := &User{ID: 42, FirstName: "John", LastName: "Doe"}
+	text := Format(
+		"Hi, ", Bold("dear ", Italic(Mention(user))), "! ",
+		"🙂 ", Link("https://go.dev", "go.dev"), " ", 1, "\n",
+		Pre("go", "a < b"), Spoiler(Underline(Italic("x"))),
+	)
+
+	assert.Equal(t, "Hi, dear John Doe! 🙂 go.dev 1\na < bx", text.String())
+	assert.Equal(t, 37, text.Len())
+	assert.Equal(t, Entities{
+		{Type: EntityBold, Offset: 4, Length: 13},
+		{Type: EntityItalic, Offset: 9, Length: 8},
+		{Type: EntityTMention, Offset: 9, Length: 8, User: user},
+		{Type: EntityTextLink, Offset: 22, Length: 6, URL: "https://go.dev"},
+		{Type: EntityCodeBlock, Offset: 31, Length: 5, Language: "go"},
+		{Type: EntitySpoiler, Offset: 36, Length: 1},
+		{Type: EntityUnderline, Offset: 36, Length: 1},
+		{Type: EntityItalic, Offset: 36, Length: 1},
+	}, text.Entities())
+
+	assert.Equal(t,
+		`Hi, <b>dear <i><a href="tg://user?id=42">John Doe</a></i></b>! 🙂 <a href="https://go.dev">go.dev</a> 1`+"\n"+
+			`<pre><code class="language-go">a &lt; b</code></pre><tg-spoiler><u><i>x</i></u></tg-spoiler>`,
+		text.HTML())
+	assert.Equal(t,
+		"Hi, *dear _[John Doe](tg://user?id=42)_*\\! 🙂 [go\\.dev](https://go.dev) 1\n"+
+			"```go\na < b\n```||___x_\r__||",
+		text.MarkdownV2())
+}
+
+func TestRenderEntities(t *testing.T) {
+	// Overlapping entities are split.
+	text, entities := "abcd", Entities{
+		{Type: EntityBold, Offset: 0, Length: 3},
+		{Type: EntityItalic, Offset: 1, Length: 3},
+		{Type: EntityHashtag, Offset: 0, Length: 4},
+	}
+	assert.Equal(t, "<b>a<i>bc</i></b><i>d</i>", renderEntities(text, entities, htmlRenderer{}))
+
+	assert.Equal(t, "`a\\`b`", Code("a`b").MarkdownV2())
+	assert.Equal(t, "[x](https://a.b/(c\\))", Link("https://a.b/(c)", "x").MarkdownV2())
+	assert.Empty(t, Bold().Entities())
+}
+
+func TestMessageFormatted(t *testing.T) {
+	m := &Message{
+		Text: "👋 Hello, #world & <friends>!",
+		Entities: Entities{
+			{Type: EntityBold, Offset: 3, Length: 13},
+			{Type: EntityItalic, Offset: 10, Length: 6},
+			{Type: EntityHashtag, Offset: 10, Length: 6},
+			{Type: EntityCode, Offset: 19, Length: 9},
+		},
+	}
+	assert.Equal(t, "👋 <b>Hello, <i>#world</i></b> &amp; <code>&lt;friends&gt;</code>!", m.HTML())
+	assert.Equal(t, "👋 *Hello, _\\#world_* & `<friends>`\\!", m.MarkdownV2())
+
+	m = &Message{
+		Caption:         "a_b",
+		CaptionEntities: Entities{{Type: EntitySpoiler, Offset: 2, Length: 1}},
+	}
+	assert.Equal(t, "a_<tg-spoiler>b</tg-spoiler>", m.HTML())
+	assert.Equal(t, "a\\_||b||", m.MarkdownV2())
+
+	text := Format(Bold("x ", Italic("y")))
+	m = &Message{Text: text.String(), Entities: text.Entities()}
+	assert.Equal(t, text.HTML(), m.HTML())
+}
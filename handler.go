@@ -5,6 +5,9 @@ package telebot
 type Handle struct {
 	Do         HandlerFunc
 	Middleware []HandlerFunc
+
+	endpoint string
+	signed   bool
 }
 
 // HandlerFunc represents a handler function, which is
@@ -56,7 +56,7 @@ func (r *ResultBase) Process(b *Bot) {
 		}
 	}
 	if r.ReplyMarkup != nil {
-		processButtons(r.ReplyMarkup.InlineKeyboard)
+		b.processButtons(r.ReplyMarkup.InlineKeyboard)
 	}
 }
 
@@ -0,0 +1,145 @@
+package telebot
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/3JoB/ulib/litefmt"
+	"github.com/cornelk/hashmap"
+)
+
+// Job is an action scheduled at the time. Jobs are kept
+// in the JobStore, so they survive restarts of the bot.
+type Job struct {
+	ID string `json:"id"`
+
+	// Kind selects the handler of the job, see HandleJob.
+	Kind string `json:"kind"`
+
+	// At is the time the job runs at. The jobs, which time
+	// passed while the bot was stopped, run once it starts.
+	At time.Time `json:"at"`
+
+	// Cron is the schedule of the recurring job, see ParseCron.
+	// The one-time jobs are removed after they run.
+	Cron string `json:"cron,omitempty"`
+
+	// Payload is the data of the job, which is
+	// JSON for the jobs scheduled by telebot.
+	Payload string `json:"payload,omitempty"`
+
+	// Retries is the number of times the one-time job was
+	// retried after flood limits or network errors.
+	Retries int `json:"retries,omitempty"`
+}
+
+// JobStore keeps the scheduled jobs.
+type JobStore interface {
+	// Save adds the job or replaces the one with the same ID.
+	Save(j Job) error
+
+	// Remove removes the job by its ID.
+	Remove(id string) error
+
+	// Jobs returns all the jobs.
+	Jobs() ([]Job, error)
+}
+
+// NewJobMemoryStore returns a JobStore, which keeps the jobs in
+// memory, so they are lost on restart. It's the default store.
+func NewJobMemoryStore() JobStore {
+	return &jobMemoryStore{m: hashmap.New[string, Job]()}
+}
+
+type jobMemoryStore struct {
+	m *hashmap.Map[string, Job]
+}
+
+func (s *jobMemoryStore) Save(j Job) error {
+	s.m.Set(j.ID, j)
+	return nil
+}
+
+func (s *jobMemoryStore) Remove(id string) error {
+	s.m.Del(id)
+	return nil
+}
+
+func (s *jobMemoryStore) Jobs() ([]Job, error) {
+	jobs := make([]Job, 0, s.m.Len())
+	s.m.Range(func(_ string, j Job) bool {
+		jobs = append(jobs, j)
+		return true
+	})
+	return jobs, nil
+}
+
+// NewJobDiskStore returns a JobStore, which keeps the jobs in the
+// JSON file at the path. The file is read once and rewritten on
+// every change.
+func NewJobDiskStore(path string) (JobStore, error) {
+	s := &jobDiskStore{path: path, m: make(map[string]Job)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if err := defaultJson.Unmarshal(data, &s.m); err != nil {
+		return nil, wrapError(err)
+	}
+	return s, nil
+}
+
+type jobDiskStore struct {
+	path string
+	mu   sync.RWMutex
+	m    map[string]Job
+}
+
+func (s *jobDiskStore) Save(j Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[j.ID] = j
+	return s.write()
+}
+
+func (s *jobDiskStore) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.m[id]; !ok {
+		return nil
+	}
+	delete(s.m, id)
+	return s.write()
+}
+
+func (s *jobDiskStore) Jobs() ([]Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jobs := make([]Job, 0, len(s.m))
+	for _, j := range s.m {
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+func (s *jobDiskStore) write() error {
+	data, err := defaultJson.Marshal(s.m)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	tmp := litefmt.PSprint(s.path, ".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return wrapError(err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
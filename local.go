@@ -0,0 +1,107 @@
+package telebot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/3JoB/ulib/litefmt"
+)
+
+// File size limits of the cloud and local Bot API servers.
+// The local server doesn't limit the size of downloads.
+const (
+	MaxUploadSize      = 50 << 20
+	MaxDownloadSize    = 20 << 20
+	MaxLocalUploadSize = 2000 << 20
+)
+
+// UploadLimit returns the maximum size of the file the bot can upload.
+// Larger files of known size fail with ErrFileTooBig before uploading.
+func (b *Bot) UploadLimit() int64 {
+	if b.local {
+		return MaxLocalUploadSize
+	}
+	return MaxUploadSize
+}
+
+// DownloadLimit returns the maximum size of the file the bot
+// can download, or 0 if it's not limited.
+func (b *Bot) DownloadLimit() int64 {
+	if b.local {
+		return 0
+	}
+	return MaxDownloadSize
+}
+
+// uploadsByPath reports whether the file is sent to the local
+// server by its path instead of being uploaded. The server
+// reads such files from the disk itself.
+func (b *Bot) uploadsByPath(f *File) bool {
+	return b.local && f.FileReader == nil && f.OnDisk()
+}
+
+// uploadTooBig reports whether the file uploaded from the disk or
+// the reader exceeds UploadLimit. Readers of unknown size pass.
+func (b *Bot) uploadTooBig(f *File) bool {
+	if f.InCloud() || f.FileURL != "" {
+		return false
+	}
+
+	size := int64(-1)
+	if f.FileReader != nil {
+		size = f.size(f.FileReader)
+	} else if stat, err := os.Stat(f.FileLocal); err == nil {
+		size = stat.Size()
+	}
+	return size > b.UploadLimit()
+}
+
+// fileURI returns the file:// URI of the file on disk.
+func fileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return "file://" + filepath.ToSlash(path)
+}
+
+// MoveToLocal logs the bot out from the cloud Bot API server and switches
+// it to the local server at the url. The bot can't log in to the cloud
+// server again for 10 minutes after that.
+func (b *Bot) MoveToLocal(url string) error {
+	if _, err := b.Logout(); err != nil {
+		return err
+	}
+	b.setServer(url, true)
+	return nil
+}
+
+// MoveToServer closes the bot instance on the current local server and
+// switches it to another local server at the url. The webhook is removed
+// first, so the old server doesn't launch the bot again after a restart.
+func (b *Bot) MoveToServer(url string) error {
+	if err := b.RemoveWebhook(); err != nil {
+		return err
+	}
+	if _, err := b.Close(); err != nil {
+		return err
+	}
+	b.setServer(url, true)
+	return nil
+}
+
+// MoveToCloud logs the bot out from the local server
+// and switches it back to the cloud Bot API server.
+func (b *Bot) MoveToCloud() error {
+	if _, err := b.Logout(); err != nil {
+		return err
+	}
+	b.setServer(DefaultApiURL, false)
+	return nil
+}
+
+func (b *Bot) setServer(url string, local bool) {
+	b.URL = strings.TrimSuffix(url, "/")
+	b.URLCache = litefmt.PSprint(b.URL, "/bot", b.Token, "/")
+	b.local = local
+}
@@ -0,0 +1,93 @@
+package telebot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.txt")
+	require.NoError(t, os.WriteFile(path, []byte("0123456789"), 0o644))
+
+	var calls []string
+	params := make(map[string]any)
+	b := newFakeBot(t, Settings{Token: "TOKEN", Local: true}, func(w http.ResponseWriter, r *http.Request) {
+		method := fakeMethod(r)
+		calls = append(calls, method)
+
+		require.False(t, strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/"))
+		if r.Method == http.MethodGet {
+			w.Write([]byte("remote"))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != io.EOF {
+			require.NoError(t, err)
+		}
+		switch method {
+		case "getFile":
+			if params["file_id"] == "relative" {
+				w.Write([]byte(`{"ok":true,"result":{"file_path":"documents/file.txt"}}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"result":{"file_path":"` + filepath.ToSlash(path) + `"}}`))
+		case "sendDocument":
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1,"document":{"file_id":"1"}}}`))
+		default:
+			w.Write([]byte(`{"ok":true,"result":true}`))
+		}
+	})
+
+	assert.Equal(t, int64(MaxLocalUploadSize), b.UploadLimit())
+	assert.Zero(t, b.DownloadLimit())
+
+	_, err := b.Send(&Chat{ID: 1}, &Document{File: FromDisk(path)})
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+filepath.ToSlash(path), params["document"])
+
+	file := &File{FileID: "1"}
+	r, err := b.FileRange(file, 2, 3)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "234", string(data))
+	assert.Equal(t, filepath.ToSlash(path), file.FilePath)
+
+	// Relative paths aren't on the disk of the bot.
+	r, err = b.File(&File{FileID: "relative"})
+	require.NoError(t, err)
+	data, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "remote", string(data))
+
+	calls = nil
+	url := b.URL
+	require.NoError(t, b.MoveToServer(url+"/other/"))
+	assert.Equal(t, []string{"deleteWebhook", "close"}, calls)
+	assert.Equal(t, url+"/other/botTOKEN/getMe", b.buildUrl("getMe"))
+
+	calls = nil
+	require.NoError(t, b.MoveToCloud())
+	assert.Equal(t, []string{"logOut"}, calls)
+	assert.Equal(t, DefaultApiURL, b.URL)
+	assert.Equal(t, int64(MaxUploadSize), b.UploadLimit())
+
+	_, err = b.File(&File{FileID: "1", FileSize: MaxDownloadSize + 1})
+	assert.ErrorIs(t, err, ErrFileTooBig)
+
+	calls = nil
+	big := File{FileReader: strings.NewReader("big"), FileSize: MaxUploadSize + 1}
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: big})
+	assert.ErrorIs(t, err, ErrFileTooBig)
+	assert.Empty(t, calls)
+}
@@ -1,10 +1,11 @@
 package telebot
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"log/slog"
 
-	"github.com/3JoB/ulib/litefmt"
-	"github.com/3JoB/unsafeConvert"
 	"github.com/rs/zerolog"
 )
 
@@ -19,6 +20,47 @@ type Logger interface {
 	OnError(error, *Context)
 }
 
+// LeveledLogger is a Logger, which supports levels and structured fields.
+// Both built-in loggers implement it. If the logger passed to Settings
+// implements it as well, the bot logs structured records through it.
+type LeveledLogger interface {
+	Logger
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// Field is a key-value pair attached to a structured log record.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Keys of the fields used by telebot.
+const (
+	FieldUpdateID   = "update_id"
+	FieldUpdateType = "update_type"
+	FieldChatID     = "chat_id"
+	FieldSenderID   = "sender_id"
+	FieldMethod     = "method"
+	FieldError      = "error"
+)
+
+// ContextFields returns the fields identifying the update
+// of the context: its ID, and chat ID if presented.
+func ContextFields(c *Context) []Field {
+	if c == nil {
+		return nil
+	}
+
+	fields := []Field{{FieldUpdateID, c.Update().ID}}
+	if chat := c.Chat(); chat != nil {
+		fields = append(fields, Field{FieldChatID, chat.ID})
+	}
+	return fields
+}
+
 type LoggerZerolog struct {
 	l zerolog.Logger
 }
@@ -55,17 +97,90 @@ func (z *LoggerZerolog) Panicf(format string, a ...any) {
 }
 
 func (z *LoggerZerolog) OnError(err error, c *Context) {
-	var message string
-	if c != nil {
-		message = litefmt.PSprint(unsafeConvert.Itoa(c.Update().ID), " ", err.Error())
-	} else {
-		message = err.Error()
+	z.Error(err.Error(), ContextFields(c)...)
+}
+
+func (z *LoggerZerolog) Debug(msg string, fields ...Field) {
+	z.log(z.l.Debug(), msg, fields)
+}
+
+func (z *LoggerZerolog) Info(msg string, fields ...Field) {
+	z.log(z.l.Info(), msg, fields)
+}
+
+func (z *LoggerZerolog) Warn(msg string, fields ...Field) {
+	z.log(z.l.Warn(), msg, fields)
+}
+
+func (z *LoggerZerolog) Error(msg string, fields ...Field) {
+	z.log(z.l.Error(), msg, fields)
+}
+
+func (z *LoggerZerolog) log(e *zerolog.Event, msg string, fields []Field) {
+	if !e.Enabled() {
+		return
+	}
+	for _, f := range fields {
+		e = e.Interface(f.Key, f.Value)
 	}
-	z.doPrint(message)
+	e.CallerSkipFrame(2).Msg(msg)
 }
 
-func (z *LoggerZerolog) doPrint(v string) {
-	if e := z.l.Debug(); e.Enabled() {
-		e.CallerSkipFrame(1).Msg(v)
+// LoggerSlog is a Logger backed by log/slog.
+type LoggerSlog struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to the given slog.Logger.
+// If l is nil, slog.Default() is used.
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &LoggerSlog{l: l}
+}
+
+func (s *LoggerSlog) Println(a ...any) {
+	s.l.Info(fmt.Sprint(a...))
+}
+
+func (s *LoggerSlog) Printf(format string, a ...any) {
+	s.l.Info(fmt.Sprintf(format, a...))
+}
+
+func (s *LoggerSlog) Panicf(format string, a ...any) {
+	s.l.Error(fmt.Sprintf(format, a...))
+}
+
+func (s *LoggerSlog) OnError(err error, c *Context) {
+	s.Error(err.Error(), ContextFields(c)...)
+}
+
+func (s *LoggerSlog) Debug(msg string, fields ...Field) {
+	s.log(slog.LevelDebug, msg, fields)
+}
+
+func (s *LoggerSlog) Info(msg string, fields ...Field) {
+	s.log(slog.LevelInfo, msg, fields)
+}
+
+func (s *LoggerSlog) Warn(msg string, fields ...Field) {
+	s.log(slog.LevelWarn, msg, fields)
+}
+
+func (s *LoggerSlog) Error(msg string, fields ...Field) {
+	s.log(slog.LevelError, msg, fields)
+}
+
+func (s *LoggerSlog) log(level slog.Level, msg string, fields []Field) {
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.Any(f.Key, f.Value)
 	}
+	s.l.LogAttrs(ctx, level, msg, attrs...)
 }
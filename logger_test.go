@@ -0,0 +1,60 @@
+package telebot
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	ll, ok := l.(LeveledLogger)
+	require.True(t, ok)
+
+	ll.Debug("hidden")
+	assert.Zero(t, buf.Len())
+
+	ll.Info("sent", Field{FieldMethod, "sendMessage"})
+	assert.Contains(t, buf.String(), `"level":"INFO"`)
+	assert.Contains(t, buf.String(), `"method":"sendMessage"`)
+
+	buf.Reset()
+	c := &Context{u: Update{ID: 7, Message: &Message{Chat: &Chat{ID: 42}}}}
+	l.OnError(errors.New("fail"), c)
+	assert.Contains(t, buf.String(), `"level":"ERROR"`)
+	assert.Contains(t, buf.String(), `"msg":"fail"`)
+	assert.Contains(t, buf.String(), `"update_id":7`)
+	assert.Contains(t, buf.String(), `"chat_id":42`)
+}
+
+func TestZeroLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewZeroLogger(&buf)
+
+	l.OnError(errors.New("fail"), nil)
+	assert.Contains(t, buf.String(), `"level":"error"`)
+	assert.Contains(t, buf.String(), `"message":"fail"`)
+}
+
+func TestVerboseSlog(t *testing.T) {
+	var buf bytes.Buffer
+	b := newFakeBot(t, Settings{
+		Verbose: true,
+		Logger:  NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+	}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+
+	_, err := b.Send(&Chat{ID: 1}, "hello")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"level":"INFO"`)
+	assert.Contains(t, buf.String(), `"msg":"telebot: sent request"`)
+	assert.Contains(t, buf.String(), `"method":"sendMessage"`)
+}
@@ -3,6 +3,7 @@ package telebot
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // ReplyMarkup controls two convenient options for bot-user communications
@@ -92,6 +93,10 @@ type Btn struct {
 	Poll            PollType `json:"request_poll,omitempty"`
 	Login           *Login   `json:"login_url,omitempty"`
 	WebApp          *WebApp  `json:"web_app,omitempty"`
+
+	// Sign and TTL make the callback data signed, see Signed.
+	Sign bool          `json:"-"`
+	TTL  time.Duration `json:"-"`
 }
 
 // Row represents an array of buttons, a row.
@@ -240,6 +245,11 @@ type InlineButton struct {
 	InlineQueryChat string  `json:"switch_inline_query_current_chat"`
 	Login           *Login  `json:"login_url,omitempty"`
 	WebApp          *WebApp `json:"web_app,omitempty"`
+
+	// Sign makes the callback data signed with Settings.CallbackSecret,
+	// which is valid for TTL, or forever if it's zero.
+	Sign bool          `json:"-"`
+	TTL  time.Duration `json:"-"`
 }
 
 // MarshalJSON implements json.Marshaler interface.
@@ -269,6 +279,8 @@ func (t *InlineButton) With(data string) *InlineButton {
 		InlineQueryChat: t.InlineQueryChat,
 		Login:           t.Login,
 		Data:            data,
+		Sign:            t.Sign,
+		TTL:             t.TTL,
 	}
 }
 
@@ -296,6 +308,8 @@ func (b Btn) Inline() *InlineButton {
 		InlineQueryChat: b.InlineQueryChat,
 		Login:           b.Login,
 		WebApp:          b.WebApp,
+		Sign:            b.Sign,
+		TTL:             b.TTL,
 	}
 }
 
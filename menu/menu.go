@@ -0,0 +1,316 @@
+// Package menu implements inline keyboard menus declared as a tree.
+//
+// Every menu level is rendered as a message with breadcrumbs and an
+// inline keyboard, which navigates to submenus, switches toggles,
+// selects radio choices or runs actions. The current position is kept
+// in the callback data, so menus survive restarts, and the values of
+// toggles and radios are kept in a Store.
+//
+//	m := menu.New("settings", "Settings")
+//	notify := m.Submenu("Notifications")
+//	notify.Toggle("Sound", "sound")
+//	freq := notify.Radio("Frequency", "freq")
+//	freq.Choice("Daily", "daily")
+//	freq.Choice("Weekly", "weekly")
+//	m.Action("Reset", func(c *tele.Context) error { ... })
+//	m.Handle(b)
+//
+//	b.Handle("/settings", m.Show)
+package menu
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+const (
+	kindSubmenu = iota
+	kindToggle
+	kindRadio
+	kindChoice
+	kindAction
+)
+
+// Item is a node of the menu tree.
+type Item struct {
+	// Text is the text of the button and the title of the submenu.
+	Text string
+
+	// Description is shown under the breadcrumbs of the submenu.
+	Description string
+
+	// Columns is the number of buttons in a row of the submenu, 1 by default.
+	Columns int
+
+	kind     int
+	key      string
+	value    string
+	action   tele.HandlerFunc
+	onChange func(c *tele.Context, value string) error
+
+	m        *Menu
+	parent   *Item
+	children []*Item
+	path     string
+}
+
+// Menu is the root of the menu tree.
+type Menu struct {
+	Item
+
+	// Unique is the unique of the menu callback endpoint.
+	Unique string
+
+	// Store keeps the values of toggles and radios.
+	// MemoryStore is used by default.
+	Store Store
+
+	// Back is the text of the button leading to the parent menu.
+	Back string
+
+	// Separator separates the titles in the breadcrumbs.
+	Separator string
+
+	// On and Off prefix the text of enabled and disabled toggles.
+	On, Off string
+
+	// Selected and Unselected prefix the text of radio choices.
+	Selected, Unselected string
+}
+
+// New returns an empty menu with the endpoint unique and the title.
+func New(unique, title string) *Menu {
+	m := &Menu{
+		Unique:     unique,
+		Store:      NewMemoryStore(),
+		Back:       "« Back",
+		Separator:  " › ",
+		On:         "✅ ",
+		Off:        "▫️ ",
+		Selected:   "🔘 ",
+		Unselected: "⚪ ",
+	}
+	m.Item = Item{Text: title, m: m, path: "/"}
+	return m
+}
+
+func (it *Item) add(child *Item) *Item {
+	child.m = it.m
+	child.parent = it
+	child.path = it.path + strconv.Itoa(len(it.children))
+	if child.kind == kindSubmenu || child.kind == kindRadio {
+		child.path += "/"
+	}
+	it.children = append(it.children, child)
+	return child
+}
+
+// Submenu adds a nested menu.
+func (it *Item) Submenu(text string) *Item {
+	return it.add(&Item{Text: text, kind: kindSubmenu})
+}
+
+// Toggle adds a button switching the boolean value of the key
+// between "1" and "".
+func (it *Item) Toggle(text, key string) *Item {
+	return it.add(&Item{Text: text, kind: kindToggle, key: key})
+}
+
+// Radio adds a submenu selecting one of its choices as the value of the key.
+// The button shows the text of the selected choice.
+func (it *Item) Radio(text, key string) *Item {
+	return it.add(&Item{Text: text, kind: kindRadio, key: key})
+}
+
+// Choice adds a choice of the value to the radio.
+func (it *Item) Choice(text, value string) *Item {
+	return it.add(&Item{Text: text, kind: kindChoice, key: it.key, value: value})
+}
+
+// Action adds a button running the handler.
+// The handler is responsible for responding to the callback.
+func (it *Item) Action(text string, h tele.HandlerFunc) *Item {
+	return it.add(&Item{Text: text, kind: kindAction, action: h})
+}
+
+// OnChange sets the function called after the value
+// of the toggle or radio is changed.
+func (it *Item) OnChange(fn func(c *tele.Context, value string) error) *Item {
+	if it.kind == kindChoice {
+		it = it.parent
+	}
+	it.onChange = fn
+	return it
+}
+
+// CallbackUnique implements tele.CallbackEndpoint.
+func (m *Menu) CallbackUnique() string {
+	return "\f" + m.Unique
+}
+
+// Handle registers the menu callback endpoint in the bot.
+func (m *Menu) Handle(b *tele.Bot) {
+	b.Handle(m, m.handle)
+}
+
+// Show shows the root menu: edits the message if the update
+// is a callback, otherwise sends a new one.
+func (m *Menu) Show(c *tele.Context) error {
+	return m.show(c, &m.Item)
+}
+
+func (m *Menu) handle(c *tele.Context) error {
+	it := m.find(c.Data())
+	if it == nil {
+		it = &m.Item
+	}
+
+	switch it.kind {
+	case kindAction:
+		return it.action(c)
+	case kindToggle:
+		value, err := m.Store.Get(c, it.key)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			value = "1"
+		} else {
+			value = ""
+		}
+		if err := it.set(c, value); err != nil {
+			return err
+		}
+		it = it.parent
+	case kindChoice:
+		if err := it.set(c, it.value); err != nil {
+			return err
+		}
+		it = it.parent
+	}
+
+	if err := m.show(c, it); err != nil {
+		return err
+	}
+	return c.Respond()
+}
+
+func (it *Item) set(c *tele.Context, value string) error {
+	if err := it.m.Store.Set(c, it.key, value); err != nil {
+		return err
+	}
+
+	owner := it
+	if it.kind == kindChoice {
+		owner = it.parent
+	}
+	if owner.onChange != nil {
+		return owner.onChange(c, value)
+	}
+	return nil
+}
+
+// find returns the item by its path, or nil if there is no such one.
+func (m *Menu) find(path string) *Item {
+	if !strings.HasPrefix(path, "/") {
+		return nil
+	}
+
+	it := &m.Item
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 || i >= len(it.children) {
+			return nil
+		}
+		it = it.children[i]
+	}
+	return it
+}
+
+func (m *Menu) show(c *tele.Context, it *Item) error {
+	text, markup, err := m.render(c, it)
+	if err != nil {
+		return err
+	}
+
+	if c.Callback() == nil {
+		_, err := c.Send(text, markup)
+		return err
+	}
+
+	err = c.Edit(text, markup)
+	if errors.Is(err, tele.ErrSameMessageContent) || errors.Is(err, tele.ErrMessageNotModified) {
+		return nil
+	}
+	return err
+}
+
+// render returns the text and the keyboard of the submenu or radio.
+func (m *Menu) render(c *tele.Context, it *Item) (string, *tele.ReplyMarkup, error) {
+	var titles []string
+	for p := it; p != nil; p = p.parent {
+		titles = append([]string{p.Text}, titles...)
+	}
+	text := strings.Join(titles, m.Separator)
+	if it.Description != "" {
+		text += "\n\n" + it.Description
+	}
+
+	markup := &tele.ReplyMarkup{}
+	btns := make([]tele.Btn, 0, len(it.children))
+	for _, child := range it.children {
+		label, err := m.label(c, child)
+		if err != nil {
+			return "", nil, err
+		}
+		btns = append(btns, markup.Data(label, m.Unique, child.path))
+	}
+
+	rows := markup.Split(max(it.Columns, 1), btns)
+	if it.parent != nil {
+		rows = append(rows, markup.Row(markup.Data(m.Back, m.Unique, it.parent.path)))
+	}
+	markup.Inline(rows...)
+
+	return text, markup, nil
+}
+
+func (m *Menu) label(c *tele.Context, it *Item) (string, error) {
+	switch it.kind {
+	case kindToggle:
+		value, err := m.Store.Get(c, it.key)
+		if err != nil {
+			return "", err
+		}
+		if value != "" {
+			return m.On + it.Text, nil
+		}
+		return m.Off + it.Text, nil
+	case kindRadio:
+		value, err := m.Store.Get(c, it.key)
+		if err != nil {
+			return "", err
+		}
+		for _, choice := range it.children {
+			if choice.value == value {
+				return it.Text + ": " + choice.Text, nil
+			}
+		}
+	case kindChoice:
+		value, err := m.Store.Get(c, it.key)
+		if err != nil {
+			return "", err
+		}
+		if value == it.value {
+			return m.Selected + it.Text, nil
+		}
+		return m.Unselected + it.Text, nil
+	}
+	return it.Text, nil
+}
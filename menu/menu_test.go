@@ -0,0 +1,120 @@
+package menu
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+type request struct {
+	Method      string
+	Text        string `json:"text"`
+	ReplyMarkup string `json:"reply_markup"`
+}
+
+func (r request) buttons(t *testing.T) (btns []string) {
+	var markup tele.ReplyMarkup
+	require.NoError(t, json.Unmarshal([]byte(r.ReplyMarkup), &markup))
+	for _, row := range markup.InlineKeyboard {
+		for _, btn := range row {
+			btns = append(btns, btn.Text+"="+btn.Data)
+		}
+	}
+	return btns
+}
+
+func TestMenu(t *testing.T) {
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		if req.Method == "answerCallbackQuery" {
+			w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	b, err := tele.NewBot(tele.Settings{URL: srv.URL, Offline: true, Synchronous: true})
+	require.NoError(t, err)
+
+	var changed []string
+	m := New("menu", "Settings")
+	notify := m.Submenu("Notifications")
+	notify.Toggle("Sound", "sound").OnChange(func(c *tele.Context, value string) error {
+		changed = append(changed, "sound="+value)
+		return nil
+	})
+	freq := notify.Radio("Frequency", "freq")
+	freq.Choice("Daily", "daily")
+	freq.Choice("Weekly", "weekly")
+	m.Action("Reset", func(c *tele.Context) error {
+		changed = append(changed, "reset")
+		return nil
+	})
+	m.Handle(b)
+
+	chat := &tele.Chat{ID: 1}
+	msg := &tele.Message{ID: 1, Chat: chat}
+	click := func(data string) request {
+		requests = nil
+		b.ProcessUpdate(tele.Update{Callback: &tele.Callback{ID: "1", Message: msg, Data: "\fmenu|" + data}})
+		require.NotEmpty(t, requests)
+		return requests[0]
+	}
+
+	b.Handle("/settings", m.Show)
+	b.ProcessUpdate(tele.Update{Message: &tele.Message{Text: "/settings", Chat: chat}})
+	require.Len(t, requests, 1)
+	assert.Equal(t, "sendMessage", requests[0].Method)
+	assert.Equal(t, "Settings", requests[0].Text)
+	assert.Equal(t, []string{"Notifications=\fmenu|/0/", "Reset=\fmenu|/1"}, requests[0].buttons(t))
+
+	req := click("/0/")
+	assert.Equal(t, "editMessageText", req.Method)
+	assert.Equal(t, "Settings › Notifications", req.Text)
+	assert.Equal(t, []string{
+		"▫️ Sound=\fmenu|/0/0",
+		"Frequency=\fmenu|/0/1/",
+		"« Back=\fmenu|/",
+	}, req.buttons(t))
+	assert.Equal(t, "answerCallbackQuery", requests[1].Method)
+
+	req = click("/0/0")
+	assert.Equal(t, "✅ Sound=\fmenu|/0/0", req.buttons(t)[0])
+	assert.Equal(t, []string{"sound=1"}, changed)
+
+	req = click("/0/1/1")
+	assert.Equal(t, "Settings › Notifications › Frequency", req.Text)
+	assert.Equal(t, []string{
+		"⚪ Daily=\fmenu|/0/1/0",
+		"🔘 Weekly=\fmenu|/0/1/1",
+		"« Back=\fmenu|/0/",
+	}, req.buttons(t))
+
+	req = click("/0/1/")
+	assert.Equal(t, "🔘 Weekly=\fmenu|/0/1/1", req.buttons(t)[1])
+
+	req = click("/0/")
+	assert.Equal(t, "Frequency: Weekly=\fmenu|/0/1/", req.buttons(t)[1])
+
+	changed = nil
+	requests = nil
+	b.ProcessUpdate(tele.Update{Callback: &tele.Callback{ID: "1", Message: msg, Data: "\fmenu|/1"}})
+	assert.Equal(t, []string{"reset"}, changed)
+	assert.Empty(t, requests)
+
+	// Unknown paths lead to the root menu.
+	req = click("/9/9")
+	assert.Equal(t, "Settings", req.Text)
+}
@@ -0,0 +1,56 @@
+package menu
+
+import (
+	"strconv"
+	"sync"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+// Store keeps the values of toggles and radios. Implement it
+// to persist the values in a database.
+type Store interface {
+	Get(c *tele.Context, key string) (string, error)
+	Set(c *tele.Context, key, value string) error
+}
+
+// MemoryStore keeps the values in memory, per chat.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewMemoryStore returns an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: make(map[string]string)}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(c *tele.Context, key string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[scope(c)+key], nil
+}
+
+// Set implements Store.
+func (s *MemoryStore) Set(c *tele.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value == "" {
+		delete(s.values, scope(c)+key)
+	} else {
+		s.values[scope(c)+key] = value
+	}
+	return nil
+}
+
+func scope(c *tele.Context) string {
+	if chat := c.Chat(); chat != nil {
+		return strconv.FormatInt(chat.ID, 10) + ":"
+	}
+	if sender := c.Sender(); sender != nil {
+		return strconv.FormatInt(sender.ID, 10) + ":"
+	}
+	return ":"
+}
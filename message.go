@@ -437,6 +437,29 @@ func (m *Message) EntityText(e MessageEntity) string {
 	return string(utf16.Decode(a[off:end]))
 }
 
+// HTML returns the text or caption of the message with its
+// entities applied, rendered for ModeHTML. Entities, which are
+// detected by Telegram automatically, like hashtags or URLs,
+// are rendered as the plain text.
+func (m *Message) HTML() string {
+	text, entities := m.formatted()
+	return renderEntities(text, entities, htmlRenderer{})
+}
+
+// MarkdownV2 returns the text or caption of the message with its
+// entities applied, rendered for ModeMarkdownV2.
+func (m *Message) MarkdownV2() string {
+	text, entities := m.formatted()
+	return renderEntities(text, entities, &markdownV2Renderer{})
+}
+
+func (m *Message) formatted() (string, Entities) {
+	if m.Text != "" {
+		return m.Text, m.Entities
+	}
+	return m.Caption, m.CaptionEntities
+}
+
 // Media returns the message's media if it contains either photo,
 // voice, audio, animation, sticker, document, video or video note.
 func (m *Message) Media() Media {
@@ -1,17 +1,72 @@
 package middleware
 
 import (
-	"github.com/3JoB/unsafeConvert"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	tele "github.com/3JoB/telebot/v2"
 )
 
+// maxLoggedText is the maximum number of characters
+// of the text or data logged for an update.
+const maxLoggedText = 64
+
 // Logger returns a middleware that logs incoming updates.
-// If no custom logger provided, log.Default() will be used.
-func Logger(logger tele.Logger) tele.HandlerFunc {
+// Every update is logged as a single record with its ID, type,
+// chat, sender and the truncated text or callback data.
+// If no custom logger provided, the bot's logger will be used.
+func Logger(logger ...tele.Logger) tele.HandlerFunc {
+	var l tele.Logger
+	if len(logger) > 0 {
+		l = logger[0]
+	}
+
 	return func(c *tele.Context) error {
-		data, _ := c.Bot().Json().MarshalIndent(c.Update(), "", "  ")
-		logger.Println(unsafeConvert.StringSlice(data))
+		l := l
+		if l == nil {
+			l = c.Bot().Logger()
+		}
+
+		fields := updateFields(c)
+		if ll, ok := l.(tele.LeveledLogger); ok {
+			ll.Info("telebot: update", fields...)
+		} else {
+			var sb strings.Builder
+			sb.WriteString("telebot: update")
+			for _, f := range fields {
+				fmt.Fprintf(&sb, " %s=%v", f.Key, f.Value)
+			}
+			l.Println(sb.String())
+		}
 		return c.Next()
 	}
 }
+
+func updateFields(c *tele.Context) []tele.Field {
+	u := c.Update()
+	fields := []tele.Field{
+		{Key: tele.FieldUpdateID, Value: u.ID},
+		{Key: tele.FieldUpdateType, Value: u.Type()},
+	}
+	if chat := c.Chat(); chat != nil {
+		fields = append(fields, tele.Field{Key: tele.FieldChatID, Value: chat.ID})
+	}
+	if sender := c.Sender(); sender != nil {
+		fields = append(fields, tele.Field{Key: tele.FieldSenderID, Value: sender.ID})
+	}
+	if text := c.Text(); text != "" {
+		fields = append(fields, tele.Field{Key: "text", Value: truncate(text)})
+	} else if data := c.Data(); data != "" {
+		fields = append(fields, tele.Field{Key: "data", Value: truncate(data)})
+	}
+	return fields
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxLoggedText {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:maxLoggedText]) + "…"
+}
@@ -0,0 +1,123 @@
+// Package metrics instruments the bot with counters and histograms.
+//
+// It doesn't depend on any metrics client. Measurements are passed to
+// the Collector interface, and the Registry collector, which exposes
+// them in the Prometheus text format, is provided by default.
+//
+// Example:
+//
+//	reg := metrics.NewRegistry()
+//	m := metrics.New(reg)
+//
+//	b, _ := tele.NewBot(tele.Settings{
+//		Token:    "...",
+//		Poller:   m.Poller(&tele.LongPoller{Timeout: 10 * time.Second}),
+//		Observer: m,
+//	})
+//
+//	http.Handle("/metrics", reg)
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+// Names of the recorded metrics.
+const (
+	UpdatesTotal       = "telebot_updates_total"
+	HandlerDuration    = "telebot_handler_duration_seconds"
+	HandlerErrorsTotal = "telebot_handler_errors_total"
+	APIRequestDuration = "telebot_api_request_duration_seconds"
+	PollerLag          = "telebot_poller_lag_seconds"
+)
+
+// Label is a name-value pair identifying the series of a metric.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Collector receives the measurements. Implement it to plug
+// in the metrics client of your choice.
+type Collector interface {
+	// Add increments the counter by the given value.
+	Add(name string, value float64, labels ...Label)
+
+	// Observe records the value in the histogram.
+	Observe(name string, value float64, labels ...Label)
+}
+
+// Metrics records the bot activity into a Collector.
+// It implements tele.Observer.
+type Metrics struct {
+	c Collector
+}
+
+// New returns Metrics recording into the given collector.
+func New(c Collector) *Metrics {
+	return &Metrics{c: c}
+}
+
+// Poller wraps the poller, so every received update is counted
+// by its type, and the delay between the update was created and
+// received is recorded as the poller lag.
+func (m *Metrics) Poller(p tele.Poller) tele.Poller {
+	return tele.NewMiddlewarePoller(p, func(u tele.Update) bool {
+		m.c.Add(UpdatesTotal, 1, Label{"type", u.Type()})
+		if t := updateTime(u); !t.IsZero() {
+			m.c.Observe(PollerLag, time.Since(t).Seconds())
+		}
+		return true
+	})
+}
+
+// OnHandle records the handler latency and errors by the endpoint.
+func (m *Metrics) OnHandle(_ *tele.Context, endpoint string, took time.Duration, err error) {
+	label := Label{"endpoint", Endpoint(endpoint)}
+	m.c.Observe(HandlerDuration, took.Seconds(), label)
+	if err != nil {
+		m.c.Add(HandlerErrorsTotal, 1, label)
+	}
+}
+
+// OnRequest records the Bot API request latency by the method and HTTP status.
+func (m *Metrics) OnRequest(method string, status int, took time.Duration, _ error) {
+	m.c.Observe(APIRequestDuration, took.Seconds(),
+		Label{"method", method},
+		Label{"status", strconv.Itoa(status)},
+	)
+}
+
+// Endpoint returns a readable form of the endpoint, trimming
+// the special prefixes of telebot events and callbacks.
+func Endpoint(endpoint string) string {
+	return strings.TrimLeft(endpoint, "\a\f")
+}
+
+func updateTime(u tele.Update) time.Time {
+	var unix int64
+	switch {
+	case u.Message != nil:
+		unix = u.Message.Unixtime
+	case u.EditedMessage != nil:
+		unix = u.EditedMessage.LastEdit
+	case u.ChannelPost != nil:
+		unix = u.ChannelPost.Unixtime
+	case u.EditedChannelPost != nil:
+		unix = u.EditedChannelPost.LastEdit
+	case u.MyChatMember != nil:
+		unix = u.MyChatMember.Unixtime
+	case u.ChatMember != nil:
+		unix = u.ChatMember.Unixtime
+	case u.ChatJoinRequest != nil:
+		unix = u.ChatJoinRequest.Unixtime
+	}
+	if unix == 0 {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
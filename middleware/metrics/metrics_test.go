@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry(0.1, 1)
+	m := New(reg)
+
+	m.OnHandle(nil, tele.OnText, 50*time.Millisecond, nil)
+	m.OnHandle(nil, tele.OnText, 2*time.Second, errors.New("oops"))
+	m.OnRequest("sendMessage", 200, 500*time.Millisecond, nil)
+	reg.Add(UpdatesTotal, 1, Label{"type", `a"b`})
+
+	var sb strings.Builder
+	_, err := reg.WriteTo(&sb)
+	assert.NoError(t, err)
+
+	expected := `# HELP telebot_api_request_duration_seconds Bot API request latency by method and HTTP status.
+# TYPE telebot_api_request_duration_seconds histogram
+telebot_api_request_duration_seconds_bucket{method="sendMessage",status="200",le="0.1"} 0
+telebot_api_request_duration_seconds_bucket{method="sendMessage",status="200",le="1"} 1
+telebot_api_request_duration_seconds_bucket{method="sendMessage",status="200",le="+Inf"} 1
+telebot_api_request_duration_seconds_sum{method="sendMessage",status="200"} 0.5
+telebot_api_request_duration_seconds_count{method="sendMessage",status="200"} 1
+# HELP telebot_handler_duration_seconds Handler latency by endpoint.
+# TYPE telebot_handler_duration_seconds histogram
+telebot_handler_duration_seconds_bucket{endpoint="text",le="0.1"} 1
+telebot_handler_duration_seconds_bucket{endpoint="text",le="1"} 1
+telebot_handler_duration_seconds_bucket{endpoint="text",le="+Inf"} 2
+telebot_handler_duration_seconds_sum{endpoint="text"} 2.05
+telebot_handler_duration_seconds_count{endpoint="text"} 2
+# HELP telebot_handler_errors_total Number of handler errors by endpoint.
+# TYPE telebot_handler_errors_total counter
+telebot_handler_errors_total{endpoint="text"} 1
+# HELP telebot_updates_total Number of received updates by type.
+# TYPE telebot_updates_total counter
+telebot_updates_total{type="a\"b"} 1
+`
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestPoller(t *testing.T) {
+	reg := NewRegistry()
+	p := New(reg).Poller(&tele.LongPoller{}).(*tele.MiddlewarePoller)
+
+	assert.True(t, p.Filter(tele.Update{Message: &tele.Message{Unixtime: time.Now().Unix()}}))
+	assert.True(t, p.Filter(tele.Update{Callback: &tele.Callback{}}))
+
+	var sb strings.Builder
+	reg.WriteTo(&sb)
+	assert.Contains(t, sb.String(), `telebot_updates_total{type="message"} 1`)
+	assert.Contains(t, sb.String(), `telebot_updates_total{type="callback_query"} 1`)
+	assert.Contains(t, sb.String(), "telebot_poller_lag_seconds_count 1")
+}
@@ -0,0 +1,225 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram buckets used by NewRegistry
+// if no custom ones are given, in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var help = map[string]string{
+	UpdatesTotal:       "Number of received updates by type.",
+	HandlerDuration:    "Handler latency by endpoint.",
+	HandlerErrorsTotal: "Number of handler errors by endpoint.",
+	APIRequestDuration: "Bot API request latency by method and HTTP status.",
+	PollerLag:          "Delay between the update creation and its receiving.",
+}
+
+const (
+	kindCounter   = "counter"
+	kindHistogram = "histogram"
+)
+
+// Registry is an in-memory Collector, which exposes the collected
+// metrics in the Prometheus text exposition format.
+type Registry struct {
+	mu       sync.Mutex
+	buckets  []float64
+	families map[string]*family
+}
+
+type family struct {
+	kind   string
+	series map[string]*series
+}
+
+type series struct {
+	labels  []Label
+	sum     float64
+	count   uint64
+	buckets []uint64
+}
+
+// NewRegistry returns an empty registry. Buckets are upper bounds
+// of histograms, DefaultBuckets are used if none are given.
+func NewRegistry(buckets ...float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Registry{
+		buckets:  buckets,
+		families: make(map[string]*family),
+	}
+}
+
+// Add implements Collector.
+func (r *Registry) Add(name string, value float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s := r.series(name, kindCounter, labels); s != nil {
+		s.sum += value
+	}
+}
+
+// Observe implements Collector.
+func (r *Registry) Observe(name string, value float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.series(name, kindHistogram, labels)
+	if s == nil {
+		return
+	}
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(r.buckets))
+	}
+
+	s.sum += value
+	s.count++
+	for i, le := range r.buckets {
+		if value <= le {
+			s.buckets[i]++
+		}
+	}
+}
+
+func (r *Registry) series(name, kind string, labels []Label) *series {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{kind: kind, series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	if f.kind != kind {
+		return nil
+	}
+
+	key := labelsKey(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: append([]Label(nil), labels...)}
+		f.series[key] = s
+	}
+	return s
+}
+
+// WriteTo writes all the metrics in the Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := r.families[name]
+		if h, ok := help[name]; ok {
+			cw.write("# HELP ", name, " ", h, "\n")
+		}
+		cw.write("# TYPE ", name, " ", f.kind, "\n")
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := f.series[key]
+			if f.kind == kindCounter {
+				cw.write(name, formatLabels(s.labels), " ", formatFloat(s.sum), "\n")
+				continue
+			}
+
+			for i, le := range r.buckets {
+				labels := append(s.labels[:len(s.labels):len(s.labels)], Label{"le", formatFloat(le)})
+				cw.write(name, "_bucket", formatLabels(labels), " ", strconv.FormatUint(s.buckets[i], 10), "\n")
+			}
+			labels := append(s.labels[:len(s.labels):len(s.labels)], Label{"le", "+Inf"})
+			cw.write(name, "_bucket", formatLabels(labels), " ", strconv.FormatUint(s.count, 10), "\n")
+			cw.write(name, "_sum", formatLabels(s.labels), " ", formatFloat(s.sum), "\n")
+			cw.write(name, "_count", formatLabels(s.labels), " ", strconv.FormatUint(s.count, 10), "\n")
+		}
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP implements http.Handler, so the registry
+// can be scraped by Prometheus directly.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w) //nolint:errcheck
+}
+
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) write(parts ...string) {
+	for _, p := range parts {
+		if cw.err != nil {
+			return
+		}
+		n, err := cw.w.WriteString(p)
+		cw.n += int64(n)
+		cw.err = err
+	}
+}
+
+func labelsKey(labels []Label) string {
+	var sb strings.Builder
+	for _, l := range labels {
+		sb.WriteString(l.Name)
+		sb.WriteByte('=')
+		sb.WriteString(l.Value)
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(l.Name)
+		sb.WriteString(`="`)
+		sb.WriteString(labelEscaper.Replace(l.Value))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,110 @@
+// Package tracing provides helpers for tele.Tracer.
+//
+// Recorder keeps spans in memory, which is useful in tests.
+// To export spans to OpenTelemetry, adapt its tracer in your code:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(parent tele.Span, name string) tele.Span {
+//		ctx := context.Background()
+//		if p, ok := parent.(otelSpan); ok {
+//			ctx = trace.ContextWithSpan(ctx, p.Span)
+//		}
+//		_, span := o.t.Start(ctx, name)
+//		return otelSpan{span}
+//	}
+package tracing
+
+import (
+	"sync"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+// Recorder is a tele.Tracer, which records spans in memory.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// Span is a span recorded by Recorder.
+type Span struct {
+	r *Recorder
+
+	Name       string
+	Parent     *Span
+	Attributes map[string]any
+	Err        error
+	Ended      bool
+}
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start implements tele.Tracer.
+func (r *Recorder) Start(parent tele.Span, name string) tele.Span {
+	s := &Span{
+		r:          r,
+		Name:       name,
+		Attributes: make(map[string]any),
+	}
+	if p, ok := parent.(*Span); ok {
+		s.Parent = p
+	}
+
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	return s
+}
+
+// Spans returns the spans recorded so far, in the order they were started.
+func (r *Recorder) Spans() []*Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Span(nil), r.spans...)
+}
+
+// Ended returns the ended spans with the given name.
+func (r *Recorder) Ended(name string) []*Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var spans []*Span
+	for _, s := range r.spans {
+		if s.Name == name && s.Ended {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+// Reset forgets all the recorded spans.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.spans = nil
+	r.mu.Unlock()
+}
+
+// SetAttribute implements tele.Span.
+func (s *Span) SetAttribute(key string, value any) {
+	s.r.mu.Lock()
+	s.Attributes[key] = value
+	s.r.mu.Unlock()
+}
+
+// SetError implements tele.Span.
+func (s *Span) SetError(err error) {
+	s.r.mu.Lock()
+	s.Err = err
+	s.r.mu.Unlock()
+}
+
+// End implements tele.Span.
+func (s *Span) End() {
+	s.r.mu.Lock()
+	s.Ended = true
+	s.r.mu.Unlock()
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+func TestRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder()
+	b, err := tele.NewBot(tele.Settings{
+		URL:         srv.URL,
+		Offline:     true,
+		Synchronous: true,
+		Tracer:      rec,
+	})
+	require.NoError(t, err)
+
+	fail := errors.New("fail")
+	b.Handle(tele.OnText, func(c *tele.Context) error {
+		assert.Same(t, b, c.Bot())
+		_, err := c.Send("pong")
+		require.NoError(t, err)
+		require.NoError(t, c.Delete())
+		return fail
+	})
+
+	b.ProcessUpdate(tele.Update{ID: 7, Message: &tele.Message{Text: "ping", Chat: &tele.Chat{ID: 42}}})
+	b.ProcessUpdate(tele.Update{ID: 8, Poll: &tele.Poll{}})
+
+	updates := rec.Ended(tele.SpanUpdate)
+	require.Len(t, updates, 2)
+
+	upd := updates[0]
+	assert.Equal(t, 7, upd.Attributes[tele.AttrUpdateID])
+	assert.Equal(t, "message", upd.Attributes[tele.AttrUpdateType])
+	assert.Equal(t, int64(42), upd.Attributes[tele.AttrChatID])
+	assert.Equal(t, tele.OnText, upd.Attributes[tele.AttrEndpoint])
+	assert.Equal(t, fail, upd.Err)
+
+	assert.Equal(t, "poll", updates[1].Attributes[tele.AttrUpdateType])
+	assert.NotContains(t, updates[1].Attributes, tele.AttrEndpoint)
+
+	requests := rec.Ended("sendMessage")
+	require.Len(t, requests, 1)
+	assert.Same(t, upd, requests[0].Parent)
+	assert.Equal(t, "sendMessage", requests[0].Attributes[tele.AttrMethod])
+	assert.Equal(t, 200, requests[0].Attributes[tele.AttrStatus])
+	assert.NoError(t, requests[0].Err)
+
+	deletes := rec.Ended("deleteMessage")
+	require.Len(t, deletes, 1)
+	assert.Same(t, upd, deletes[0].Parent)
+}
@@ -0,0 +1,107 @@
+package telebot
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Migrate remembers that the group with ID from has been upgraded
+// to the supergroup with ID to. With Settings.AutoMigrate enabled,
+// every subsequent request addressed to from is sent to to instead.
+//
+// It's useful to restore migrations previously persisted by the
+// OnMigration handler after a restart.
+func (b *Bot) Migrate(from, to int64) {
+	if from == 0 || to == 0 || from == to {
+		return
+	}
+	b.migrations.Set(from, to)
+}
+
+// MigratedTo returns the supergroup ID the given chat was migrated to,
+// if such migration is known to the bot.
+func (b *Bot) MigratedTo(chatID int64) (int64, bool) {
+	return b.migrations.Get(chatID)
+}
+
+// redirect replaces chat_id of the map payload with the ID of the
+// supergroup it was migrated to. It reports whether the payload was changed.
+func (b *Bot) redirect(payload any) bool {
+	if !b.autoMigrate {
+		return false
+	}
+
+	from, ok := chatIDOf(payload)
+	if !ok {
+		return false
+	}
+	to, ok := b.MigratedTo(from)
+	if !ok {
+		return false
+	}
+
+	switch p := payload.(type) {
+	case map[string]any:
+		p["chat_id"] = strconv.FormatInt(to, 10)
+	case map[string]string:
+		p["chat_id"] = strconv.FormatInt(to, 10)
+	}
+	return true
+}
+
+// migrate handles the GroupError returned for the payload. It records
+// the migration, notifies the OnMigration handler and reports whether
+// the request should be retried with the redirected payload.
+func (b *Bot) migrate(err error, payload any) bool {
+	if !b.autoMigrate || err == nil {
+		return false
+	}
+
+	var ge GroupError
+	if !errors.As(err, &ge) || ge.MigratedTo == 0 {
+		return false
+	}
+
+	from, ok := chatIDOf(payload)
+	if !ok {
+		return false
+	}
+
+	b.Migrate(from, ge.MigratedTo)
+	b.notifyMigration(from, ge.MigratedTo)
+	return b.redirect(payload)
+}
+
+// notifyMigration runs the OnMigration handler with the context
+// that looks like the original migration service message.
+func (b *Bot) notifyMigration(from, to int64) {
+	handler, ok := b.handlers[OnMigration]
+	if !ok {
+		return
+	}
+
+	c := b.NewContext(Update{Message: &Message{
+		Chat:        &Chat{ID: from, Type: ChatGroup},
+		MigrateFrom: from,
+		MigrateTo:   to,
+	}})
+	b.runHandler(handler, c)
+}
+
+func chatIDOf(payload any) (int64, bool) {
+	var s string
+	switch p := payload.(type) {
+	case map[string]any:
+		switch v := p["chat_id"].(type) {
+		case int64:
+			return v, true
+		case string:
+			s = v
+		}
+	case map[string]string:
+		s = p["chat_id"]
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	return id, err == nil
+}
@@ -0,0 +1,52 @@
+package telebot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigration(t *testing.T) {
+	b, err := NewBot(Settings{Offline: true, Synchronous: true, AutoMigrate: true})
+	require.NoError(t, err)
+
+	params := map[string]any{"chat_id": "-1"}
+	assert.False(t, b.redirect(params))
+
+	var from, to int64
+	b.Handle(OnMigration, func(c *Context) error {
+		from, to = c.Migration()
+		return nil
+	})
+
+	gerr := GroupError{err: ErrGroupMigrated, MigratedTo: -1001}
+	assert.False(t, b.migrate(errors.New("other"), params))
+	assert.True(t, b.migrate(gerr, params))
+	assert.Equal(t, "-1001", params["chat_id"])
+	assert.Equal(t, int64(-1), from)
+	assert.Equal(t, int64(-1001), to)
+
+	id, ok := b.MigratedTo(-1)
+	assert.True(t, ok)
+	assert.Equal(t, int64(-1001), id)
+
+	strParams := map[string]string{"chat_id": "-1"}
+	assert.True(t, b.redirect(strParams))
+	assert.Equal(t, "-1001", strParams["chat_id"])
+
+	sigParams := map[string]any{"chat_id": int64(-1)}
+	assert.True(t, b.redirect(sigParams))
+	assert.Equal(t, "-1001", sigParams["chat_id"])
+
+	b.ProcessUpdate(Update{Message: &Message{Chat: &Chat{ID: -2}, MigrateTo: -1002}})
+	id, ok = b.MigratedTo(-2)
+	assert.True(t, ok)
+	assert.Equal(t, int64(-1002), id)
+
+	b.autoMigrate = false
+	params = map[string]any{"chat_id": "-1"}
+	assert.False(t, b.redirect(params))
+	assert.False(t, b.migrate(gerr, params))
+}
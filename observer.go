@@ -0,0 +1,18 @@
+package telebot
+
+import "time"
+
+// Observer is notified about the bot activity. It allows collecting
+// metrics without wrapping every handler and API call manually.
+//
+// Observer methods are called synchronously, so they must be fast
+// and safe for concurrent use.
+type Observer interface {
+	// OnHandle is called after the handler of the endpoint has finished.
+	// The err is either the middleware error or the handler one.
+	OnHandle(c *Context, endpoint string, took time.Duration, err error)
+
+	// OnRequest is called after the Bot API method has been requested.
+	// The status is the HTTP status code, or 0 if no response was received.
+	OnRequest(method string, status int, took time.Duration, err error)
+}
@@ -33,6 +33,9 @@ const (
 
 	// RemoveKeyboard = ReplyMarkup.RemoveKeyboard
 	RemoveKeyboard
+
+	// Split = SendOptions.Split
+	Split
 )
 
 // Placeholder is used to set input field placeholder as a send option.
@@ -82,6 +85,13 @@ type SendOptions struct {
 
 	// HasSpoiler marks the message as containing a spoiler.
 	HasSpoiler bool
+
+	// Split splits too long texts into several messages, see SendSplit.
+	// Send returns the last of the sent messages.
+	Split bool
+
+	// span is the parent span of the requests.
+	span Span
 }
 
 func (og *SendOptions) copy() *SendOptions {
@@ -128,6 +138,8 @@ func extractOptions(how []any) *SendOptions {
 				opts.ReplyMarkup.RemoveKeyboard = true
 			case Protected:
 				opts.Protected = true
+			case Split:
+				opts.Split = true
 			default:
 				panic("telebot: unsupported flag-option")
 			}
@@ -135,6 +147,8 @@ func extractOptions(how []any) *SendOptions {
 			opts.ParseMode = opt
 		case Entities:
 			opts.Entities = opt
+		case spanOption:
+			opts.span = opt.Span
 		default:
 			panic("telebot: unsupported send-option")
 		}
@@ -152,6 +166,10 @@ func (b *Bot) embedSendOptions(params map[string]any, opt *SendOptions) {
 		return
 	}
 
+	if opt.span != nil {
+		params[spanParam] = opt.span
+	}
+
 	if opt.ReplyTo != nil && opt.ReplyTo.ID != 0 {
 		params["reply_to_message_id"] = opt.ReplyTo.ID
 	}
@@ -172,7 +190,7 @@ func (b *Bot) embedSendOptions(params map[string]any, opt *SendOptions) {
 		delete(params, "parse_mode")
 		entities, _ := b.json.Marshal(opt.Entities)
 
-		if params["caption"] != "" {
+		if caption, _ := params["caption"].(string); caption != "" {
 			params["caption_entities"] = unsafeConvert.StringPointer(entities)
 		} else {
 			params["entities"] = unsafeConvert.StringPointer(entities)
@@ -184,7 +202,7 @@ func (b *Bot) embedSendOptions(params map[string]any, opt *SendOptions) {
 	}
 
 	if opt.ReplyMarkup != nil {
-		processButtons(opt.ReplyMarkup.InlineKeyboard)
+		b.processButtons(opt.ReplyMarkup.InlineKeyboard)
 		replyMarkup, _ := b.json.Marshal(opt.ReplyMarkup)
 		params["reply_markup"] = unsafeConvert.StringPointer(replyMarkup)
 	}
@@ -202,7 +220,7 @@ func (b *Bot) embedSendOptions(params map[string]any, opt *SendOptions) {
 	}
 }
 
-func processButtons(keys [][]InlineButton) {
+func (b *Bot) processButtons(keys [][]InlineButton) {
 	if keys == nil || len(keys) < 1 || len(keys[0]) < 1 {
 		return
 	}
@@ -219,6 +237,10 @@ func processButtons(keys [][]InlineButton) {
 					key.Data = "\f" + key.Unique + "|" + data
 				}
 			}
+			if key.Sign && len(b.callbackSecret) > 0 {
+				key.Data = b.signCallback(key.Data, key.TTL)
+			}
+			key.Data = b.compactCallback(key.Data)
 		}
 	}
 }
@@ -0,0 +1,134 @@
+package telebot
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/3JoB/ulib/litefmt"
+)
+
+// PageSource returns the buttons of the items from offset to
+// offset+limit and the total number of items.
+type PageSource func(c *Context, offset, limit int) (items []Btn, total int, err error)
+
+// PageSlice returns a PageSource over the fixed list of buttons.
+func PageSlice(items []Btn) PageSource {
+	return func(_ *Context, offset, limit int) ([]Btn, int, error) {
+		if offset > len(items) {
+			offset = len(items)
+		}
+		return items[offset:min(offset+limit, len(items))], len(items), nil
+	}
+}
+
+// Paginator renders long lists of items as an inline keyboard with
+// pages. Below the items, it adds navigation buttons: previous page,
+// current page number and next page. On navigation, it edits the
+// reply markup of the message in place.
+//
+//	p := tele.NewPaginator(b, "admins", func(c *tele.Context, offset, limit int) ([]tele.Btn, int, error) {
+//		...
+//	})
+//
+//	b.Handle("/admins", func(c *tele.Context) error {
+//		markup, err := p.Markup(c, 0)
+//		if err != nil {
+//			return err
+//		}
+//		return c.Send("Admins:", markup)
+//	})
+type Paginator struct {
+	// Unique is the unique of the navigation callback endpoint.
+	Unique string
+
+	// PerPage is the number of items on a page, 10 by default.
+	// It's at least 1.
+	PerPage int
+
+	// Columns is the number of items in a row, 1 by default.
+	Columns int
+
+	// Prev and Next are the texts of navigation buttons,
+	// "«" and "»" by default.
+	Prev, Next string
+
+	source PageSource
+}
+
+// NewPaginator creates a paginator over the source and registers
+// its navigation callback endpoint in the bot.
+func NewPaginator(b *Bot, unique string, source PageSource) *Paginator {
+	p := &Paginator{
+		Unique:  unique,
+		PerPage: 10,
+		Columns: 1,
+		Prev:    "«",
+		Next:    "»",
+		source:  source,
+	}
+	b.Handle(p, p.handle)
+	return p
+}
+
+// CallbackUnique implements CallbackEndpoint.
+func (p *Paginator) CallbackUnique() string {
+	return "\f" + p.Unique
+}
+
+// Markup returns the inline keyboard with the page, starting from zero.
+// The page is clamped to the existing ones.
+func (p *Paginator) Markup(c *Context, page int) (*ReplyMarkup, error) {
+	page = max(page, 0)
+	perPage := max(p.PerPage, 1)
+	items, total, err := p.source(c, page*perPage, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := max((total+perPage-1)/perPage, 1)
+	if page >= pages {
+		page = pages - 1
+		items, total, err = p.source(c, page*perPage, perPage)
+		if err != nil {
+			return nil, err
+		}
+		pages = max((total+perPage-1)/perPage, 1)
+	}
+
+	markup := &ReplyMarkup{}
+	rows := markup.Split(max(p.Columns, 1), items)
+
+	if pages > 1 {
+		var nav Row
+		if page > 0 {
+			nav = append(nav, markup.Data(p.Prev, p.Unique, strconv.Itoa(page-1)))
+		}
+		nav = append(nav, markup.Data(litefmt.PSprint(strconv.Itoa(page+1), "/", strconv.Itoa(pages)), p.Unique))
+		if page < pages-1 {
+			nav = append(nav, markup.Data(p.Next, p.Unique, strconv.Itoa(page+1)))
+		}
+		rows = append(rows, nav)
+	}
+
+	markup.Inline(rows...)
+	return markup, nil
+}
+
+func (p *Paginator) handle(c *Context) error {
+	// The button with the current page number has no data.
+	page, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond()
+	}
+
+	markup, err := p.Markup(c, page)
+	if err != nil {
+		return err
+	}
+
+	err = c.Edit(markup)
+	if err != nil && !errors.Is(err, ErrSameMessageContent) && !errors.Is(err, ErrMessageNotModified) {
+		return err
+	}
+	return c.Respond()
+}
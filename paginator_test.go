@@ -0,0 +1,76 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginator(t *testing.T) {
+	var methods []string
+	var edited ReplyMarkup
+	b := newFakeBot(t, Settings{Synchronous: true}, func(w http.ResponseWriter, r *http.Request) {
+		method := fakeMethod(r)
+		methods = append(methods, method)
+
+		if method == "editMessageReplyMarkup" {
+			var params struct {
+				ReplyMarkup string `json:"reply_markup"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+			require.NoError(t, json.Unmarshal([]byte(params.ReplyMarkup), &edited))
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	var items []Btn
+	for i := 1; i <= 7; i++ {
+		items = append(items, Btn{Text: strconv.Itoa(i), Unique: "item", Data: strconv.Itoa(i)})
+	}
+	p := NewPaginator(b, "page", PageSlice(items))
+	p.PerPage, p.Columns = 3, 2
+
+	markup, err := p.Markup(nil, 0)
+	require.NoError(t, err)
+	require.Len(t, markup.InlineKeyboard, 3)
+	assert.Len(t, markup.InlineKeyboard[0], 2)
+	assert.Len(t, markup.InlineKeyboard[1], 1)
+	nav := markup.InlineKeyboard[2]
+	require.Len(t, nav, 2)
+	assert.Equal(t, "1/3", nav[0].Text)
+	assert.Equal(t, "»", nav[1].Text)
+	assert.Equal(t, "1", nav[1].Data)
+
+	markup, err = p.Markup(nil, 10)
+	require.NoError(t, err)
+	require.Len(t, markup.InlineKeyboard, 2)
+	assert.Equal(t, "7", markup.InlineKeyboard[0][0].Text)
+	assert.Equal(t, "«", markup.InlineKeyboard[1][0].Text)
+	assert.Equal(t, "3/3", markup.InlineKeyboard[1][1].Text)
+
+	msg := &Message{ID: 1, Chat: &Chat{ID: 1}}
+	b.ProcessUpdate(Update{Callback: &Callback{ID: "1", Message: msg, Data: "\fpage|1"}})
+	assert.Equal(t, []string{"editMessageReplyMarkup", "answerCallbackQuery"}, methods)
+	require.Len(t, edited.InlineKeyboard, 3)
+	assert.Equal(t, "4", edited.InlineKeyboard[0][0].Text)
+	assert.Equal(t, "2/3", edited.InlineKeyboard[2][1].Text)
+	assert.Equal(t, "\fpage|0", edited.InlineKeyboard[2][0].Data)
+
+	methods = nil
+	b.ProcessUpdate(Update{Callback: &Callback{ID: "2", Message: msg, Data: "\fpage"}})
+	assert.Equal(t, []string{"answerCallbackQuery"}, methods)
+
+	// Zero PerPage shows one item on a page.
+	p.PerPage = 0
+	markup, err = p.Markup(nil, 1)
+	require.NoError(t, err)
+	require.Len(t, markup.InlineKeyboard, 2)
+	assert.Equal(t, "2", markup.InlineKeyboard[0][0].Text)
+	assert.Equal(t, "2/7", markup.InlineKeyboard[1][1].Text)
+}
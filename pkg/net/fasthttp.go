@@ -9,19 +9,31 @@ import (
 	"github.com/3JoB/telebot/v2/pkg/json"
 )
 
+// streamBufferSize is the size of the response, above
+// which the streamed response body isn't buffered.
+const streamBufferSize = 64 << 10
+
 type FastHTTP struct {
 	client       *fasthttp.Client
+	stream       *fasthttp.Client
 	json         json.Json
 	requestPool  *sync.Pool
 	responsePool *sync.Pool
 }
 
 func NewFastHTTPClient() NetFrame {
+	dial := fasthttpproxy.FasthttpProxyHTTPDialer()
 	f := &FastHTTP{
 		client: &fasthttp.Client{
 			NoDefaultUserAgentHeader:      true,
 			DisableHeaderNamesNormalizing: false,
-			Dial:                          fasthttpproxy.FasthttpProxyHTTPDialer(),
+			Dial:                          dial,
+		},
+		stream: &fasthttp.Client{
+			NoDefaultUserAgentHeader: true,
+			Dial:                     dial,
+			MaxResponseBodySize:      streamBufferSize,
+			StreamResponseBody:       true,
 		},
 		requestPool:  &sync.Pool{},
 		responsePool: &sync.Pool{},
@@ -45,6 +57,7 @@ func (f *FastHTTP) Acquire() (NetRequest, NetResponse) {
 
 	r.resp = f.acquireResponse()
 	r.client = f.client
+	r.stream = f.stream
 	r.acquire()
 	return r, r.resp
 }
@@ -14,6 +14,7 @@ type FastHTTPRequest struct {
 	w        *bytes.Buffer
 	f        io.ReadWriteCloser
 	client   *fasthttp.Client
+	stream   *fasthttp.Client
 	request  *fasthttp.Request
 	response *fasthttp.Response
 	resp     *Response
@@ -39,6 +40,10 @@ func (f *FastHTTPRequest) SetContentType(v string) {
 	f.request.Header.Set("Content-Type", v)
 }
 
+func (f *FastHTTPRequest) SetHeader(key, value string) {
+	f.request.Header.Set(key, value)
+}
+
 func (f *FastHTTPRequest) SetWriter(w *bytes.Buffer) {
 	f.w = w
 }
@@ -51,11 +56,13 @@ func (f *FastHTTPRequest) Write(b []byte) {
 	f.request.BodyWriter().Write(b) //nolint:errcheck
 }
 
+// WriteFile streams the body from r while the request is sent,
+// using the chunked transfer encoding.
 func (f *FastHTTPRequest) WriteFile(content string, r io.Reader) error {
 	f.SetContentType(content)
 	f.MethodPOST()
-	_, err := io.Copy(f.request.BodyWriter(), r)
-	return err
+	f.request.SetBodyStream(r, -1)
+	return nil
 }
 
 func (f *FastHTTPRequest) WriteJson(v any) error {
@@ -97,12 +104,51 @@ END:
 	return err
 }
 
+// DoStream executes the request with the streaming client. The response
+// is detached from the request, and it's released when the body is closed.
+func (f *FastHTTPRequest) DoStream() (io.ReadCloser, error) {
+	f.request.Header.Set("User-Agent", UA)
+
+	if err := f.stream.Do(f.request, f.response); err != nil {
+		return nil, err
+	}
+	f.resp.code = f.response.StatusCode()
+
+	body := &streamBody{resp: f.response}
+	f.response = nil
+	return body, nil
+}
+
+type streamBody struct {
+	resp *fasthttp.Response
+}
+
+func (s *streamBody) Read(p []byte) (int, error) {
+	if s.resp == nil || s.resp.BodyStream() == nil {
+		return 0, io.EOF
+	}
+	return s.resp.BodyStream().Read(p)
+}
+
+func (s *streamBody) Close() error {
+	if s.resp == nil {
+		return nil
+	}
+	err := s.resp.CloseBodyStream()
+	fasthttp.ReleaseResponse(s.resp)
+	s.resp = nil
+	return err
+}
+
 func (f *FastHTTPRequest) Reset() {
 	fasthttp.ReleaseRequest(f.request)
-	fasthttp.ReleaseResponse(f.response)
+	if f.response != nil {
+		fasthttp.ReleaseResponse(f.response)
+	}
 	f.request = nil
 	f.response = nil
 	f.client = nil
+	f.stream = nil
 	f.f = nil
 	f.w = nil
 	f.resp = nil
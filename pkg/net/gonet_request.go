@@ -35,6 +35,10 @@ func (g *GoNetRequest) SetContentType(v string) {
 	g.r = g.r.SetHeader("Content-Type", v)
 }
 
+func (g *GoNetRequest) SetHeader(key, value string) {
+	g.r = g.r.SetHeader(key, value)
+}
+
 func (g *GoNetRequest) SetWriter(w *bytes.Buffer) {
 	g.w = w
 }
@@ -99,6 +103,26 @@ END:
 	return err
 }
 
+func (g *GoNetRequest) DoStream() (io.ReadCloser, error) {
+	var (
+		err      error
+		response *resty.Response
+	)
+	g.r = g.r.SetHeader("User-Agent", UA).SetDoNotParseResponse(true)
+
+	if g.method == "POST" {
+		response, err = g.r.Post(g.uri)
+	} else {
+		response, err = g.r.Get(g.uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	g.resp.code = response.StatusCode()
+	return response.RawBody(), nil
+}
+
 func (g *GoNetRequest) Reset() {
 	g.uri = ""
 	g.method = ""
@@ -42,6 +42,9 @@ type NetRequest interface {
 	// Set the requested URI address
 	SetRequestURI(v string)
 
+	// Set a request header.
+	SetHeader(key, value string)
+
 	// Set a Writer. When this Writer is passed in,
 	// the data will be written directly to the Writer
 	// after the request is completed instead of passing in the Response.
@@ -65,6 +68,11 @@ type NetRequest interface {
 	// Execute request.
 	Do() error
 
+	// Execute request and return the response body, which is read
+	// from the connection as it's consumed instead of being buffered.
+	// The body stays valid after the release and must be closed.
+	DoStream() (io.ReadCloser, error)
+
 	// Release() will clear the data in the current pointer.
 	// It is recommended to call it within the Release() method instead
 	// of calling it externally.
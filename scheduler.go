@@ -0,0 +1,527 @@
+package telebot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Kinds of the jobs scheduled by telebot.
+const (
+	JobSend   = "send"
+	JobEdit   = "edit"
+	JobDelete = "delete"
+	JobUnpin  = "unpin"
+)
+
+// ErrJobKind is returned on scheduling a job with an unknown kind.
+var ErrJobKind = errors.New("telebot: job kind is not handled")
+
+// jobRetries is the number of times the one-time job is retried,
+// and jobRetryDelay is the delay of the first retry after a network
+// error, doubled for every next one.
+var (
+	jobRetries    = 8
+	jobRetryDelay = 5 * time.Second
+)
+
+// Schedule sends the message at the time. The message is anything
+// Send accepts except for albums, and the files of media must not be
+// readers, as they can't be stored. The jobs run while the bot is
+// started and are kept in Settings.JobStore.
+func (b *Bot) Schedule(at time.Time, to Recipient, what any, opts ...any) (*Job, error) {
+	return b.scheduleMessage(Job{Kind: JobSend, At: at}, to, nil, what, opts)
+}
+
+// ScheduleCron sends the message on the cron schedule, see ParseCron.
+func (b *Bot) ScheduleCron(spec string, to Recipient, what any, opts ...any) (*Job, error) {
+	return b.scheduleMessage(Job{Kind: JobSend, Cron: spec}, to, nil, what, opts)
+}
+
+// ScheduleEdit edits the message at the time. The new content
+// is anything Edit accepts, like for Schedule.
+func (b *Bot) ScheduleEdit(at time.Time, msg Editable, what any, opts ...any) (*Job, error) {
+	return b.scheduleMessage(Job{Kind: JobEdit, At: at}, nil, msg, what, opts)
+}
+
+// ScheduleDelete deletes the message at the time.
+func (b *Bot) ScheduleDelete(at time.Time, msg Editable) (*Job, error) {
+	return b.scheduleMessage(Job{Kind: JobDelete, At: at}, nil, msg, nil, nil)
+}
+
+// ScheduleUnpin unpins the message of the chat at the time,
+// or the most recent pinned message if it's not given.
+func (b *Bot) ScheduleUnpin(at time.Time, chat *Chat, messageID ...int) (*Job, error) {
+	p := scheduledUnpin{ChatID: chat.ID}
+	if len(messageID) > 0 {
+		p.MessageID = messageID[0]
+	}
+	data, err := b.json.Marshal(p)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return b.ScheduleJob(Job{Kind: JobUnpin, At: at, Payload: string(data)})
+}
+
+// ScheduleJob schedules the job of any kind handled with HandleJob.
+// The ID of the job is generated, and the time of the recurring job
+// is taken from its schedule, if they aren't set.
+func (b *Bot) ScheduleJob(j Job) (*Job, error) {
+	if b.scheduler.handler(j.Kind) == nil {
+		return nil, ErrJobKind
+	}
+	if j.Cron != "" {
+		cron, err := ParseCron(j.Cron)
+		if err != nil {
+			return nil, err
+		}
+		if j.At.IsZero() {
+			j.At = cron.Next(time.Now())
+		}
+		if j.At.IsZero() {
+			return nil, fmt.Errorf("telebot: cron spec %q never fires", j.Cron)
+		}
+	}
+	if j.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return nil, wrapError(err)
+		}
+		j.ID = hex.EncodeToString(id)
+	}
+
+	if err := b.scheduler.add(j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// HandleJob sets the handler of the jobs of the kind. Custom kinds allow
+// to schedule any actions, which survive restarts, and override the
+// handlers of the kinds scheduled by telebot. Errors of the handlers
+// are passed to OnError. The one-time jobs failed with FloodError or
+// a network error are retried later, the other ones are removed.
+//
+//	b.HandleJob("report", func(b *tele.Bot, j tele.Job) error { ... })
+//	b.ScheduleJob(tele.Job{Kind: "report", Cron: "@daily"})
+func (b *Bot) HandleJob(kind string, h func(*Bot, Job) error) {
+	b.scheduler.mu.Lock()
+	defer b.scheduler.mu.Unlock()
+	b.scheduler.handlers[kind] = h
+}
+
+// Unschedule cancels the job by its ID.
+func (b *Bot) Unschedule(id string) error {
+	return b.scheduler.remove(id)
+}
+
+// Jobs returns the scheduled jobs.
+func (b *Bot) Jobs() ([]Job, error) {
+	jobs, err := b.scheduler.store.Jobs()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return jobs, nil
+}
+
+// scheduledMessage is the payload of the jobs sending,
+// editing and deleting messages.
+type scheduledMessage struct {
+	To      string         `json:"to,omitempty"`
+	Message *StoredMessage `json:"message,omitempty"`
+	Type    string         `json:"type,omitempty"`
+	What    string         `json:"what,omitempty"`
+	Options *SendOptions   `json:"options,omitempty"`
+}
+
+type scheduledUnpin struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int   `json:"message_id,omitempty"`
+}
+
+func (b *Bot) scheduleMessage(j Job, to Recipient, msg Editable, what any, opts []any) (*Job, error) {
+	var p scheduledMessage
+	if to != nil {
+		p.To = to.Recipient()
+	}
+	if msg != nil {
+		msgID, chatID := msg.MessageSig()
+		p.Message = &StoredMessage{MessageID: msgID, ChatID: chatID}
+	}
+
+	if what != nil {
+		p.Type = contentType(what)
+		if p.Type == "" {
+			return nil, fmt.Errorf("telebot: %T can't be scheduled", what)
+		}
+		if m, ok := what.(Media); ok && m.MediaFile().FileReader != nil {
+			return nil, errors.New("telebot: files from readers can't be scheduled")
+		}
+		data, err := b.json.Marshal(what)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		p.What = string(data)
+		p.Options = extractOptions(opts)
+	}
+
+	data, err := b.json.Marshal(p)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	j.Payload = string(data)
+	return b.ScheduleJob(j)
+}
+
+// contentType returns the name of the type of the scheduled
+// message content, or an empty string if it's not supported.
+func contentType(what any) string {
+	switch what.(type) {
+	case string:
+		return "text"
+	case *ReplyMarkup:
+		return "markup"
+	case *Photo:
+		return "photo"
+	case *Audio:
+		return "audio"
+	case *Document:
+		return "document"
+	case *Sticker:
+		return "sticker"
+	case *Video:
+		return "video"
+	case *Animation:
+		return "animation"
+	case *Voice:
+		return "voice"
+	case *VideoNote:
+		return "video_note"
+	case Location, *Location:
+		return "location"
+	case *Venue:
+		return "venue"
+	case *Poll:
+		return "poll"
+	case *Dice:
+		return "dice"
+	case *Game:
+		return "game"
+	case *Invoice:
+		return "invoice"
+	}
+	return ""
+}
+
+// content decodes the content of the scheduled message.
+func (p scheduledMessage) content(b *Bot) (any, error) {
+	var what any
+	switch p.Type {
+	case "text":
+		var s string
+		what = &s
+	case "markup":
+		what = &ReplyMarkup{}
+	case "photo":
+		// Photo decodes the photo sizes of the API instead of its own fields.
+		type photo Photo
+		var v photo
+		if err := b.json.Unmarshal([]byte(p.What), &v); err != nil {
+			return nil, wrapError(err)
+		}
+		return (*Photo)(&v), nil
+	case "audio":
+		what = &Audio{}
+	case "document":
+		what = &Document{}
+	case "sticker":
+		what = &Sticker{}
+	case "video":
+		what = &Video{}
+	case "animation":
+		what = &Animation{}
+	case "voice":
+		what = &Voice{}
+	case "video_note":
+		what = &VideoNote{}
+	case "location":
+		what = &Location{}
+	case "venue":
+		what = &Venue{}
+	case "poll":
+		what = &Poll{}
+	case "dice":
+		what = &Dice{}
+	case "game":
+		what = &Game{}
+	case "invoice":
+		what = &Invoice{}
+	default:
+		return nil, fmt.Errorf("telebot: unknown type %q of the scheduled message", p.Type)
+	}
+
+	if err := b.json.Unmarshal([]byte(p.What), what); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if s, ok := what.(*string); ok {
+		return *s, nil
+	}
+	return what, nil
+}
+
+func (p scheduledMessage) options() []any {
+	if p.Options == nil {
+		return nil
+	}
+	return []any{p.Options}
+}
+
+// recipientID is the stored recipient of the scheduled message.
+type recipientID string
+
+func (r recipientID) Recipient() string {
+	return string(r)
+}
+
+func runSendJob(b *Bot, j Job) error {
+	var p scheduledMessage
+	if err := b.json.Unmarshal([]byte(j.Payload), &p); err != nil {
+		return wrapError(err)
+	}
+	what, err := p.content(b)
+	if err != nil {
+		return err
+	}
+	_, err = b.Send(recipientID(p.To), what, p.options()...)
+	return err
+}
+
+func runEditJob(b *Bot, j Job) error {
+	var p scheduledMessage
+	if err := b.json.Unmarshal([]byte(j.Payload), &p); err != nil {
+		return wrapError(err)
+	}
+	what, err := p.content(b)
+	if err != nil {
+		return err
+	}
+	if l, ok := what.(*Location); ok {
+		what = *l
+	}
+	_, err = b.Edit(p.Message, what, p.options()...)
+	return err
+}
+
+func runDeleteJob(b *Bot, j Job) error {
+	var p scheduledMessage
+	if err := b.json.Unmarshal([]byte(j.Payload), &p); err != nil {
+		return wrapError(err)
+	}
+	return b.Delete(p.Message)
+}
+
+func runUnpinJob(b *Bot, j Job) error {
+	var p scheduledUnpin
+	if err := b.json.Unmarshal([]byte(j.Payload), &p); err != nil {
+		return wrapError(err)
+	}
+	if p.MessageID == 0 {
+		return b.Unpin(&Chat{ID: p.ChatID})
+	}
+	return b.Unpin(&Chat{ID: p.ChatID}, p.MessageID)
+}
+
+// scheduler runs the stored jobs on timers while the bot is started.
+type scheduler struct {
+	b     *Bot
+	store JobStore
+
+	mu       sync.Mutex
+	handlers map[string]func(*Bot, Job) error
+	running  bool
+	timers   map[string]*jobTimer
+	active   map[string]bool // running jobs, which aren't removed
+}
+
+type jobTimer struct {
+	*time.Timer
+}
+
+func newScheduler(b *Bot, store JobStore) *scheduler {
+	if store == nil {
+		store = NewJobMemoryStore()
+	}
+	return &scheduler{
+		b:     b,
+		store: store,
+		handlers: map[string]func(*Bot, Job) error{
+			JobSend:   runSendJob,
+			JobEdit:   runEditJob,
+			JobDelete: runDeleteJob,
+			JobUnpin:  runUnpinJob,
+		},
+		timers: make(map[string]*jobTimer),
+		active: make(map[string]bool),
+	}
+}
+
+// handler returns the handler of the job kind, or nil if it's not handled.
+func (s *scheduler) handler(kind string) func(*Bot, Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handlers[kind]
+}
+
+// start arms the timers of the stored jobs.
+func (s *scheduler) start() {
+	jobs, err := s.store.Jobs()
+	if err != nil {
+		s.b.OnError(wrapError(err), nil)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = true
+	for _, j := range jobs {
+		s.arm(j)
+	}
+}
+
+// stop stops the timers, the jobs stay in the store.
+func (s *scheduler) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	for id, t := range s.timers {
+		t.Stop()
+		delete(s.timers, id)
+	}
+}
+
+func (s *scheduler) add(j Job) error {
+	if err := s.store.Save(j); err != nil {
+		return wrapError(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.arm(j)
+	return nil
+}
+
+func (s *scheduler) remove(id string) error {
+	s.mu.Lock()
+	if t, ok := s.timers[id]; ok {
+		t.Stop()
+		delete(s.timers, id)
+	}
+	delete(s.active, id)
+	s.mu.Unlock()
+
+	if err := s.store.Remove(id); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// arm starts the timer of the job, the lock must be held.
+func (s *scheduler) arm(j Job) {
+	if !s.running {
+		return
+	}
+	if t, ok := s.timers[j.ID]; ok {
+		t.Stop()
+	}
+
+	t := &jobTimer{}
+	t.Timer = time.AfterFunc(time.Until(j.At), func() {
+		s.run(j, t)
+	})
+	s.timers[j.ID] = t
+}
+
+func (s *scheduler) run(j Job, t *jobTimer) {
+	s.mu.Lock()
+	if s.timers[j.ID] != t {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.timers, j.ID)
+	s.active[j.ID] = true
+	h := s.handlers[j.Kind]
+	s.mu.Unlock()
+
+	var err error
+	if h == nil {
+		err = fmt.Errorf("telebot: job kind %q is not handled", j.Kind)
+	} else {
+		err = h(s.b, j)
+	}
+	if err != nil {
+		s.b.OnError(err, nil)
+	}
+
+	// The job is rescheduled under the lock,
+	// so it isn't saved again once removed.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active[j.ID] {
+		return
+	}
+	delete(s.active, j.ID)
+
+	if err := s.reschedule(j, err); err != nil {
+		s.b.OnError(err, nil)
+	}
+}
+
+// reschedule saves the next run of the job failed with the error,
+// if any, or removes the job. The lock must be held.
+func (s *scheduler) reschedule(j Job, err error) error {
+	var next time.Time
+	if j.Cron != "" {
+		cron, err := ParseCron(j.Cron)
+		if err != nil {
+			return err
+		}
+		next = cron.Next(time.Now())
+	} else if delay, ok := retryDelay(err, j.Retries); ok {
+		next = time.Now().Add(delay)
+		j.Retries++
+	}
+
+	if next.IsZero() {
+		if err := s.store.Remove(j.ID); err != nil {
+			return wrapError(err)
+		}
+		return nil
+	}
+
+	j.At = next
+	if err := s.store.Save(j); err != nil {
+		return wrapError(err)
+	}
+	s.arm(j)
+	return nil
+}
+
+// retryDelay returns the delay before the retry of the job failed
+// with the error, and reports whether the job is retried.
+func retryDelay(err error, retries int) (time.Duration, bool) {
+	if err == nil || retries >= jobRetries {
+		return 0, false
+	}
+	var flood FloodError
+	if errors.As(err, &flood) {
+		return time.Duration(flood.RetryAfter) * time.Second, true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return jobRetryDelay << retries, true
+	}
+	return 0, false
+}
@@ -0,0 +1,144 @@
+package telebot
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls = make(chan map[string]any, 10)
+	)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		params := map[string]any{"method": fakeMethod(r)}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		calls <- params
+		if params["method"] == "deleteMessage" || params["method"] == "unpinChatMessage" {
+			w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1},"photo":[{"file_id":"p"}]}}`))
+	}
+
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	newBot := func() *Bot {
+		store, err := NewJobDiskStore(path)
+		require.NoError(t, err)
+		return newFakeBot(t, Settings{JobStore: store}, handler)
+	}
+
+	b := newBot()
+	soon := time.Now().Add(20 * time.Millisecond)
+	chat := &Chat{ID: 1}
+
+	_, err := b.Schedule(soon, chat, "hello", Silent, &ReplyMarkup{InlineKeyboard: [][]InlineButton{{{Text: "a", URL: "https://a"}}}})
+	require.NoError(t, err)
+	_, err = b.Schedule(soon, chat, &Photo{File: FromURL("https://photo"), Caption: "caption"})
+	require.NoError(t, err)
+	_, err = b.ScheduleDelete(soon, StoredMessage{MessageID: "2", ChatID: 1})
+	require.NoError(t, err)
+	_, err = b.ScheduleUnpin(soon, chat, 3)
+	require.NoError(t, err)
+	canceled, err := b.ScheduleEdit(soon, StoredMessage{MessageID: "4", ChatID: 1}, "edited")
+	require.NoError(t, err)
+	require.NoError(t, b.Unschedule(canceled.ID))
+
+	_, err = b.Schedule(soon, chat, FromReader(strings.NewReader("x")))
+	assert.Error(t, err)
+	_, err = b.ScheduleJob(Job{Kind: "unknown", At: soon})
+	assert.ErrorIs(t, err, ErrJobKind)
+
+	jobs, err := b.Jobs()
+	require.NoError(t, err)
+	assert.Len(t, jobs, 4)
+
+	// The jobs are restored by the new bot and run once it starts.
+	b = newBot()
+	b.scheduler.start()
+	defer b.scheduler.stop()
+
+	got := make(map[string]map[string]any)
+	for i := 0; i < 4; i++ {
+		select {
+		case params := <-calls:
+			got[params["method"].(string)] = params
+		case <-time.After(time.Second):
+			t.Fatal("jobs didn't run")
+		}
+	}
+	assert.Equal(t, "hello", got["sendMessage"]["text"])
+	assert.Equal(t, true, got["sendMessage"]["disable_notification"])
+	assert.Contains(t, got["sendMessage"]["reply_markup"], "https://a")
+	assert.Equal(t, "https://photo", got["sendPhoto"]["photo"])
+	assert.Equal(t, "caption", got["sendPhoto"]["caption"])
+	assert.Equal(t, "2", got["deleteMessage"]["message_id"])
+	assert.EqualValues(t, 3, got["unpinChatMessage"]["message_id"])
+
+	require.Eventually(t, func() bool {
+		jobs, err := b.Jobs()
+		return err == nil && len(jobs) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Recurring jobs are rescheduled after they run.
+	runs := make(chan Job, 10)
+	b.HandleJob("tick", func(b *Bot, j Job) error {
+		runs <- j
+		return nil
+	})
+	tick, err := b.ScheduleJob(Job{Kind: "tick", Cron: "@every 10ms", Payload: "data"})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		select {
+		case j := <-runs:
+			assert.Equal(t, "data", j.Payload)
+		case <-time.After(time.Second):
+			t.Fatal("recurring job didn't run")
+		}
+	}
+	require.NoError(t, b.Unschedule(tick.ID))
+	jobs, err = b.Jobs()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	// One-time jobs are retried after flood limits and network errors.
+	defer func(d time.Duration) { jobRetryDelay = d }(jobRetryDelay)
+	jobRetryDelay = time.Millisecond
+	failures := []error{
+		FloodError{err: ErrTooManyRequests},
+		wrapError(&net.OpError{Op: "dial", Err: errors.New("refused")}),
+		ErrChatNotFound,
+	}
+	retries := make(chan Job, 10)
+	b.HandleJob("flaky", func(b *Bot, j Job) error {
+		retries <- j
+		return failures[j.Retries]
+	})
+	_, err = b.ScheduleJob(Job{Kind: "flaky", At: time.Now()})
+	require.NoError(t, err)
+	for i := range failures {
+		select {
+		case j := <-retries:
+			assert.Equal(t, i, j.Retries)
+		case <-time.After(time.Second):
+			t.Fatal("failed job isn't retried")
+		}
+	}
+	require.Eventually(t, func() bool {
+		jobs, err := b.Jobs()
+		return err == nil && len(jobs) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,520 @@
+package telebot
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Telegram limits on the length of texts, in UTF-16 code units.
+const (
+	MaxMessageLength = 4096
+	MaxCaptionLength = 1024
+)
+
+// SendSplit sends the text, splitting it into several messages if it's
+// longer than MaxMessageLength. The text can be either a string in the
+// parse mode of the options or the bot, or a formatted *Text.
+//
+// The media with the caption longer than MaxCaptionLength is split too:
+// it's sent with the first part of the caption, and the other parts
+// follow as text messages.
+//
+// The parts are split on paragraph, line or word boundaries, if possible.
+// HTML tags, MarkdownV2 constructs and entities are closed at the end
+// of a part and reopened at the beginning of the next one.
+// The message is replied to with the first part only, and the reply
+// markup is attached to the last one.
+//
+// It returns the messages sent so far, including if an error occurs.
+func (b *Bot) SendSplit(to Recipient, what any, opts ...any) ([]Message, error) {
+	if to == nil {
+		return nil, ErrBadRecipient
+	}
+	return b.sendSplit(to, what, extractOptions(opts))
+}
+
+// splittable reports whether SendSplit supports the content.
+func splittable(what any) bool {
+	switch what.(type) {
+	case string, *Text:
+		return true
+	}
+	return captionOf(what) != nil
+}
+
+// captionOf returns the caption of the media, or nil
+// if the content has no caption.
+func captionOf(what any) *string {
+	switch m := what.(type) {
+	case *Photo:
+		return &m.Caption
+	case *Audio:
+		return &m.Caption
+	case *Document:
+		return &m.Caption
+	case *Video:
+		return &m.Caption
+	case *Animation:
+		return &m.Caption
+	case *Voice:
+		return &m.Caption
+	}
+	return nil
+}
+
+func (b *Bot) sendSplit(to Recipient, what any, opt *SendOptions) ([]Message, error) {
+	caption := captionOf(what)
+	if caption == nil {
+		parts, err := b.splitParts(what, opt, MaxMessageLength)
+		if err != nil {
+			return nil, err
+		}
+		return b.sendParts(to, parts, opt, false)
+	}
+
+	sendable, ok := what.(Sendable)
+	if !ok {
+		return nil, ErrUnsupportedWhat
+	}
+	parts, err := b.splitParts(*caption, opt, MaxCaptionLength)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaOpt := opt.copy()
+	mediaOpt.Split = false
+	if len(parts) > 1 {
+		mediaOpt.ReplyMarkup = nil
+	}
+	switch part := parts[0].(type) {
+	case string:
+		*caption = part
+	case *Text:
+		*caption = part.String()
+		mediaOpt.Entities = part.entities
+	}
+
+	msg, err := sendable.Send(b, to, mediaOpt)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := b.sendParts(to, parts[1:], opt, true)
+	return append([]Message{*msg}, msgs...), err
+}
+
+// splitParts splits the text into parts no longer than the limit.
+func (b *Bot) splitParts(what any, opt *SendOptions, limit int) ([]any, error) {
+	var parts []any
+
+	switch object := what.(type) {
+	case string:
+		mode := b.parseMode
+		if opt.ParseMode != ModeDefault {
+			mode = opt.ParseMode
+		}
+		if len(opt.Entities) > 0 {
+			t := Format(object)
+			t.entities = opt.Entities
+			for _, p := range t.Split(limit) {
+				parts = append(parts, p)
+			}
+			break
+		}
+		for _, p := range SplitText(object, mode, limit) {
+			parts = append(parts, p)
+		}
+	case *Text:
+		for _, p := range object.Split(limit) {
+			parts = append(parts, p)
+		}
+	default:
+		return nil, ErrUnsupportedWhat
+	}
+
+	return parts, nil
+}
+
+// sendParts sends the parts of the text, which follow
+// the already sent message if followed is true.
+func (b *Bot) sendParts(to Recipient, parts []any, opt *SendOptions, followed bool) ([]Message, error) {
+	msgs := make([]Message, 0, len(parts))
+	for i, part := range parts {
+		partOpt := opt.copy()
+		partOpt.Split = false
+		partOpt.Entities = nil
+		if i > 0 || followed {
+			partOpt.ReplyTo = nil
+		}
+		if i < len(parts)-1 {
+			partOpt.ReplyMarkup = nil
+		}
+
+		var (
+			msg *Message
+			err error
+		)
+		switch part := part.(type) {
+		case string:
+			msg, err = b.sendText(to, part, partOpt)
+		case *Text:
+			msg, err = part.Send(b, to, partOpt)
+		}
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, *msg)
+	}
+
+	return msgs, nil
+}
+
+// SplitText splits the text in the parse mode into parts, which are
+// no longer than the limit after parsing, the same way SendSplit does.
+// ModeMarkdown is split as the plain text.
+func SplitText(text string, mode ParseMode, limit int) []string {
+	var atoms []atom
+	switch mode {
+	case ModeHTML:
+		atoms = htmlAtoms(text)
+	case ModeMarkdownV2:
+		atoms = markdownV2Atoms(text)
+	default:
+		atoms = plainAtoms(text)
+	}
+
+	cuts := splitAtoms(atoms, limit)
+	if len(cuts) == 1 {
+		return []string{text}
+	}
+
+	parts := make([]string, 0, len(cuts))
+	var stack []int
+	start := 0
+	for _, cut := range cuts {
+		var sb strings.Builder
+		for _, i := range stack {
+			sb.WriteString(atoms[i].raw)
+		}
+		for i := start; i < cut; i++ {
+			sb.WriteString(atoms[i].raw)
+			stack = applyAtom(atoms, i, stack)
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			sb.WriteString(atoms[atoms[stack[i]].pair].raw)
+		}
+		parts = append(parts, sb.String())
+		start = cut
+	}
+
+	return parts
+}
+
+// Split splits the text into parts, which are no longer than the limit,
+// clipping the entities to the parts they belong to.
+func (t *Text) Split(limit int) []*Text {
+	text := t.String()
+	atoms := plainAtoms(text)
+
+	cuts := splitAtoms(atoms, limit)
+	if len(cuts) == 1 {
+		return []*Text{t}
+	}
+
+	parts := make([]*Text, 0, len(cuts))
+	offset, start := 0, 0
+	for _, cut := range cuts {
+		part := new(Text)
+		for _, a := range atoms[start:cut] {
+			part.appendString(a.raw)
+		}
+
+		end := offset + part.length
+		for _, e := range t.entities {
+			from, to := max(e.Offset, offset), min(e.Offset+e.Length, end)
+			if from >= to {
+				continue
+			}
+			e.Offset, e.Length = from-offset, to-from
+			part.entities = append(part.entities, e)
+		}
+
+		parts = append(parts, part)
+		offset, start = end, cut
+	}
+
+	return parts
+}
+
+const (
+	atomText = iota
+	atomOpen
+	atomClose
+	atomMarkup
+)
+
+// atom is the smallest piece of the text, which can't be split:
+// either a single visible character, or markup.
+type atom struct {
+	kind int
+	raw  string
+	text string // visible text, for atomText only
+	name string // tag name, for atomOpen and atomClose
+	pair int    // matching atom of atomOpen and atomClose, or -1
+}
+
+// applyAtom updates the stack of indices of the open atoms
+// with the i-th atom.
+func applyAtom(atoms []atom, i int, stack []int) []int {
+	switch atoms[i].kind {
+	case atomOpen:
+		return append(stack, i)
+	case atomClose:
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j] == atoms[i].pair {
+				return append(stack[:j], stack[j+1:]...)
+			}
+		}
+	}
+	return stack
+}
+
+// splitAtoms returns the ends of the parts the atoms should be split to.
+// The last end is always len(atoms).
+func splitAtoms(atoms []atom, limit int) (cuts []int) {
+	const (
+		breakNone = iota
+		breakWord
+		breakLine
+		breakParagraph
+	)
+
+	start := 0
+	for start < len(atoms) {
+		var (
+			length  int
+			cut     = len(atoms)
+			best    = -1
+			bestLvl = breakNone
+			prev    string
+		)
+		for i := start; i < len(atoms); i++ {
+			a := atoms[i]
+			if a.kind != atomText {
+				continue
+			}
+
+			length += utf16Len(a.text)
+			if length > limit {
+				cut = i
+				break
+			}
+
+			lvl := breakNone
+			switch {
+			case a.text == "\n" && prev == "\n":
+				lvl = breakParagraph
+			case a.text == "\n":
+				lvl = breakLine
+			case a.text == " " || a.text == "\t":
+				lvl = breakWord
+			}
+			if length*2 < limit {
+				// Don't leave the part too short because of a
+				// stronger break too close to its beginning.
+				lvl = min(lvl, breakWord)
+			}
+			if lvl != breakNone && lvl >= bestLvl {
+				best, bestLvl = i+1, lvl
+			}
+			prev = a.text
+		}
+
+		if cut < len(atoms) {
+			if best > start {
+				cut = best
+			} else if cut == start {
+				// A single character is longer than the limit.
+				cut++
+			}
+			// Markup opening right before the break goes to the next
+			// part, and closing right after it stays in this one.
+			for cut > start+1 && atoms[cut-1].kind == atomOpen {
+				cut--
+			}
+			for cut < len(atoms) && atoms[cut].kind == atomClose {
+				cut++
+			}
+		}
+
+		cuts = append(cuts, cut)
+		start = cut
+	}
+
+	if len(cuts) == 0 {
+		cuts = append(cuts, len(atoms))
+	}
+	return cuts
+}
+
+func plainAtoms(text string) []atom {
+	atoms := make([]atom, 0, len(text))
+	for _, r := range text {
+		s := string(r)
+		atoms = append(atoms, atom{kind: atomText, raw: s, text: s, pair: -1})
+	}
+	return atoms
+}
+
+// pairAtoms matches the open and close atoms by their names.
+// Unmatched ones become plain markup.
+func pairAtoms(atoms []atom) []atom {
+	var stack []int
+	for i := range atoms {
+		switch atoms[i].kind {
+		case atomOpen:
+			stack = append(stack, i)
+		case atomClose:
+			atoms[i].kind = atomMarkup
+			for j := len(stack) - 1; j >= 0; j-- {
+				if o := stack[j]; atoms[o].name == atoms[i].name {
+					atoms[o].pair, atoms[i].pair = i, o
+					atoms[i].kind = atomClose
+					stack = append(stack[:j], stack[j+1:]...)
+					break
+				}
+			}
+		}
+	}
+	for _, o := range stack {
+		atoms[o].kind = atomMarkup
+	}
+	return atoms
+}
+
+func htmlAtoms(text string) []atom {
+	var atoms []atom
+	for len(text) > 0 {
+		switch text[0] {
+		case '<':
+			end := strings.IndexByte(text, '>')
+			if end < 0 {
+				break
+			}
+			raw := text[:end+1]
+			text = text[end+1:]
+
+			kind := atomOpen
+			tag := strings.TrimPrefix(raw[1:end], "/")
+			if len(tag) < end-1 {
+				kind = atomClose
+			}
+			name, _, _ := strings.Cut(strings.TrimSpace(tag), " ")
+			atoms = append(atoms, atom{kind: kind, raw: raw, name: strings.ToLower(name), pair: -1})
+			continue
+		case '&':
+			end := strings.IndexByte(text, ';')
+			if end < 0 || end > 10 {
+				break
+			}
+			raw := text[:end+1]
+			text = text[end+1:]
+			atoms = append(atoms, atom{kind: atomText, raw: raw, text: html.UnescapeString(raw), pair: -1})
+			continue
+		}
+
+		_, n := utf8.DecodeRuneInString(text)
+		atoms = append(atoms, atom{kind: atomText, raw: text[:n], text: text[:n], pair: -1})
+		text = text[n:]
+	}
+	return pairAtoms(atoms)
+}
+
+func markdownV2Atoms(text string) []atom {
+	var (
+		atoms []atom
+		open  = make(map[string]bool)
+		code  string // "`" or "```" inside of code
+	)
+
+	toggle := func(marker string) {
+		kind := atomOpen
+		if open[marker] {
+			kind = atomClose
+		}
+		open[marker] = !open[marker]
+		atoms = append(atoms, atom{kind: kind, raw: marker, name: marker, pair: -1})
+	}
+
+	for len(text) > 0 {
+		if text[0] == '\\' && len(text) > 1 {
+			_, n := utf8.DecodeRuneInString(text[1:])
+			atoms = append(atoms, atom{kind: atomText, raw: text[:n+1], text: text[1 : n+1], pair: -1})
+			text = text[n+1:]
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "```") && (code == "" || code == "```"):
+			if code == "" {
+				raw := "```"
+				if end := strings.IndexByte(text, '\n'); end >= 0 && !strings.ContainsAny(text[3:end], "` ") {
+					raw = text[:end+1]
+				}
+				atoms = append(atoms, atom{kind: atomOpen, raw: raw, name: "```", pair: -1})
+				text, code = text[len(raw):], "```"
+			} else {
+				atoms = append(atoms, atom{kind: atomClose, raw: "```", name: "```", pair: -1})
+				text, code = text[3:], ""
+			}
+			continue
+		case text[0] == '`' && (code == "" || code == "`"):
+			toggle("`")
+			text = text[1:]
+			if code == "" {
+				code = "`"
+			} else {
+				code = ""
+			}
+			continue
+		case code != "":
+		case text[0] == '\r':
+			atoms = append(atoms, atom{kind: atomMarkup, raw: "\r", pair: -1})
+			text = text[1:]
+			continue
+		case strings.HasPrefix(text, "||"), strings.HasPrefix(text, "__"):
+			toggle(text[:2])
+			text = text[2:]
+			continue
+		case text[0] == '*' || text[0] == '_' || text[0] == '~':
+			toggle(text[:1])
+			text = text[1:]
+			continue
+		case strings.HasPrefix(text, "!["):
+			atoms = append(atoms, atom{kind: atomOpen, raw: "![", name: "[", pair: -1})
+			text = text[2:]
+			continue
+		case text[0] == '[':
+			atoms = append(atoms, atom{kind: atomOpen, raw: "[", name: "[", pair: -1})
+			text = text[1:]
+			continue
+		case strings.HasPrefix(text, "]("):
+			end := 2
+			for end < len(text) && text[end] != ')' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(text))
+			atoms = append(atoms, atom{kind: atomClose, raw: text[:end], name: "[", pair: -1})
+			text = text[end:]
+			continue
+		}
+
+		_, n := utf8.DecodeRuneInString(text)
+		atoms = append(atoms, atom{kind: atomText, raw: text[:n], text: text[:n], pair: -1})
+		text = text[n:]
+	}
+	return pairAtoms(atoms)
+}
@@ -0,0 +1,100 @@
+package telebot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	assert.Equal(t, []string{"short"}, SplitText("short", ModeDefault, 10))
+
+	assert.Equal(t,
+		[]string{"first line\n", "second line"},
+		SplitText("first line\nsecond line", ModeDefault, 15))
+	assert.Equal(t,
+		[]string{"one two\n\n", "three four five"},
+		SplitText("one two\n\nthree four five", ModeDefault, 16))
+	assert.Equal(t,
+		[]string{"abcde", "fghij", "k"},
+		SplitText("abcdefghijk", ModeDefault, 5))
+
+	assert.Equal(t,
+		[]string{"<b>a &amp; </b>", `<b>b <a href="x">c</a></b>`},
+		SplitText(`<b>a &amp; b <a href="x">c</a></b>`, ModeHTML, 5))
+
+	assert.Equal(t,
+		[]string{"*bold \\* ~x~ *", "*[link](https://a\\)) end*"},
+		SplitText("*bold \\* ~x~ [link](https://a\\)) end*", ModeMarkdownV2, 12))
+	assert.Equal(t,
+		[]string{"```go\na := 1\n```", "```go\nb := 2```"},
+		SplitText("```go\na := 1\nb := 2```", ModeMarkdownV2, 9))
+}
+
+func TestTextSplit(t *testing.T) {
+	text := Format("Hi ", Bold("dear 🙂 friend"), " bye")
+	parts := text.Split(10)
+	require.Len(t, parts, 3)
+
+	assert.Equal(t, "Hi dear ", parts[0].String())
+	assert.Equal(t, Entities{{Type: EntityBold, Offset: 3, Length: 5}}, parts[0].Entities())
+	assert.Equal(t, "🙂 friend ", parts[1].String())
+	assert.Equal(t, Entities{{Type: EntityBold, Offset: 0, Length: 9}}, parts[1].Entities())
+	assert.Equal(t, "bye", parts[2].String())
+	assert.Empty(t, parts[2].Entities())
+}
+
+func TestBotSendSplit(t *testing.T) {
+	var texts []string
+	b := newFakeBot(t, Settings{}, func(w http.ResponseWriter, r *http.Request) {
+		var params struct{ Text string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		texts = append(texts, params.Text)
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d}}`, len(texts))
+	})
+
+	long := strings.Repeat("word ", MaxMessageLength/5) + "end"
+	msgs, err := b.SendSplit(&Chat{ID: 1}, long)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, 2, msgs[1].ID)
+	assert.Equal(t, long, texts[0]+texts[1])
+
+	texts = nil
+	msg, err := b.Send(&Chat{ID: 1}, "short", Split)
+	require.NoError(t, err)
+	assert.Equal(t, 1, msg.ID)
+	assert.Equal(t, []string{"short"}, texts)
+
+	// Split doesn't affect other content.
+	texts = nil
+	msg, err = b.Send(&Chat{ID: 1}, Cube, Split)
+	require.NoError(t, err)
+	assert.Equal(t, 1, msg.ID)
+	assert.Equal(t, []string{""}, texts)
+}
+
+func TestBotSendSplitCaption(t *testing.T) {
+	var methods, texts []string
+	b := newFakeBot(t, Settings{}, func(w http.ResponseWriter, r *http.Request) {
+		var params struct{ Text, Caption string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		methods = append(methods, fakeMethod(r))
+		texts = append(texts, params.Caption+params.Text)
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"photo":[{"file_id":"p"}]}}`, len(texts))
+	})
+
+	long := strings.Repeat("word ", MaxCaptionLength/4) + "end"
+	photo := &Photo{File: FromURL("https://example.com/p.jpg"), Caption: long}
+	msg, err := b.Send(&Chat{ID: 1}, photo, Split)
+	require.NoError(t, err)
+	assert.Equal(t, 2, msg.ID)
+	assert.Equal(t, []string{"sendPhoto", "sendMessage"}, methods)
+	assert.LessOrEqual(t, len(texts[0]), MaxCaptionLength)
+	assert.Equal(t, long, texts[0]+texts[1])
+}
@@ -1,6 +1,9 @@
 package telebot
 
 import (
+	"fmt"
+	"unicode"
+
 	"github.com/3JoB/unsafeConvert"
 
 	"github.com/3JoB/telebot/v2/pkg/params"
@@ -14,20 +17,66 @@ const (
 	StickerCustomEmoji = "custom_emoji"
 )
 
+// StickerFormat is the format of a sticker file.
+type StickerFormat = string
+
+const (
+	StickerStatic   StickerFormat = "static"
+	StickerAnimated StickerFormat = "animated"
+	StickerVideo    StickerFormat = "video"
+)
+
+// MaxStickersInSet is the maximum number of stickers
+// a sticker set can be created with.
+const MaxStickersInSet = 50
+
 // StickerSet represents a sticker set.
 type StickerSet struct {
-	Type         StickerSetType `json:"sticker_type"`
-	Name         string         `json:"name"`
-	Title        string         `json:"title"`
-	Emojis       string         `json:"emojis"`
-	Animated     bool           `json:"is_animated"`
-	Video        bool           `json:"is_video"`
-	Stickers     []Sticker      `json:"stickers"`
-	Thumbnail    *Photo         `json:"thumbnail"`
-	PNG          *File          `json:"png_sticker"`
-	TGS          *File          `json:"tgs_sticker"`
-	WebM         *File          `json:"webm_sticker"`
-	MaskPosition *MaskPosition  `json:"mask_position"`
+	Type      StickerSetType `json:"sticker_type"`
+	Name      string         `json:"name"`
+	Title     string         `json:"title"`
+	Animated  bool           `json:"is_animated"`
+	Video     bool           `json:"is_video"`
+	Stickers  []Sticker      `json:"stickers"`
+	Thumbnail *Photo         `json:"thumbnail"`
+
+	// Input is the list of stickers the set is created with,
+	// up to MaxStickersInSet, see CreateStickerSet.
+	Input []InputSticker `json:"-"`
+
+	// NeedsRepainting makes the custom emoji of the created set be
+	// repainted to the text color in messages, the color of the status
+	// emoji in statuses, and to the white color in chat photos.
+	NeedsRepainting bool `json:"-"`
+
+	// Deprecated: use Input instead. The sticker is converted
+	// to an InputSticker of the corresponding format.
+	Emojis       string        `json:"emojis"`
+	PNG          *File         `json:"png_sticker"`
+	TGS          *File         `json:"tgs_sticker"`
+	WebM         *File         `json:"webm_sticker"`
+	MaskPosition *MaskPosition `json:"mask_position"`
+}
+
+// InputSticker describes a sticker to be added to a sticker set.
+type InputSticker struct {
+	// Sticker is the file of the sticker. Animated and video
+	// stickers can't be uploaded via HTTP URL.
+	Sticker File `json:"-"`
+
+	// Format is the format of the sticker file.
+	Format StickerFormat `json:"format"`
+
+	// Emojis associated with the sticker, 1-20 items.
+	Emojis []string `json:"emoji_list"`
+
+	// (Optional) Position where the mask should be placed on faces.
+	// For mask stickers only.
+	MaskPosition *MaskPosition `json:"mask_position,omitempty"`
+
+	// (Optional) Search keywords of the sticker, 0-20 items with total
+	// length of up to 64 characters. For regular and custom emoji stickers only.
+	Keywords []string `json:"keywords,omitempty"`
 }
 
 // MaskPosition describes the position on faces where
@@ -49,13 +98,115 @@ const (
 	FeatureChin     MaskFeature = "chin"
 )
 
+// inputStickers converts the deprecated single sticker fields.
+func (s *StickerSet) inputStickers() []InputSticker {
+	if len(s.Input) > 0 {
+		return s.Input
+	}
+
+	in := InputSticker{Emojis: splitEmojis(s.Emojis), MaskPosition: s.MaskPosition}
+	switch {
+	case s.PNG != nil:
+		in.Sticker, in.Format = *s.PNG, StickerStatic
+	case s.TGS != nil:
+		in.Sticker, in.Format = *s.TGS, StickerAnimated
+	case s.WebM != nil:
+		in.Sticker, in.Format = *s.WebM, StickerVideo
+	default:
+		return nil
+	}
+	return []InputSticker{in}
+}
+
+// splitEmojis splits the string into separate emojis, keeping
+// modifiers, variation selectors, joined sequences and flags
+// together with their base emoji.
+func splitEmojis(s string) (emojis []string) {
+	var (
+		start   = -1
+		joined  bool
+		regions int
+	)
+	for i, r := range s {
+		switch {
+		case r == '\u200d':
+			joined = true
+			continue
+		case r == '\ufe0e' || r == '\ufe0f' || r == '\u20e3' ||
+			r >= 0x1f3fb && r <= 0x1f3ff || r >= 0xe0020 && r <= 0xe007f:
+			continue
+		case r >= 0x1f1e6 && r <= 0x1f1ff:
+			regions++
+			if regions%2 == 0 {
+				continue
+			}
+		case unicode.IsSpace(r):
+			if start >= 0 {
+				emojis = append(emojis, s[start:i])
+			}
+			start, joined, regions = -1, false, 0
+			continue
+		default:
+			regions = 0
+		}
+
+		if joined {
+			joined = false
+			continue
+		}
+		if start >= 0 {
+			emojis = append(emojis, s[start:i])
+		}
+		start = i
+	}
+	if start >= 0 {
+		emojis = append(emojis, s[start:])
+	}
+	return emojis
+}
+
+// inputSticker is the InputSticker with the sticker file
+// represented as the file ID, URL or attachment.
+type inputSticker struct {
+	InputSticker
+	Sticker string `json:"sticker"`
+}
+
+// inputSticker puts the file of the sticker to the files map,
+// if it's uploaded, and returns the sticker ready to marshal.
+func (b *Bot) inputSticker(i int, s InputSticker, files map[string]File) (inputSticker, error) {
+	in := inputSticker{InputSticker: s}
+	switch f := s.Sticker; {
+	case f.InCloud():
+		in.Sticker = f.FileID
+	case f.FileURL != "":
+		in.Sticker = f.FileURL
+	case b.uploadsByPath(&f):
+		in.Sticker = fileURI(f.FileLocal)
+	case f.OnDisk() || f.FileReader != nil:
+		name := "sticker" + unsafeConvert.IntToString(i)
+		in.Sticker = "attach://" + name
+		files[name] = f
+	default:
+		return in, fmt.Errorf("telebot: sticker #%d does not exist", i)
+	}
+	return in, nil
+}
+
 // UploadSticker uploads a PNG file with a sticker for later use.
 func (b *Bot) UploadSticker(to Recipient, png *File) (*File, error) {
+	return b.UploadStickerFile(to, StickerStatic, png)
+}
+
+// UploadStickerFile uploads a sticker file of the format for later use
+// in CreateStickerSet and AddStickerToSet.
+func (b *Bot) UploadStickerFile(to Recipient, format StickerFormat, sticker *File) (*File, error) {
 	files := map[string]File{
-		"png_sticker": *png,
+		"sticker": *sticker,
 	}
 	params := map[string]any{
-		"user_id": to.Recipient(),
+		"user_id":        to.Recipient(),
+		"sticker_format": format,
 	}
 
 	data, err := b.sendFiles("uploadStickerFile", files, params)
@@ -86,30 +237,36 @@ func (b *Bot) StickerSet(name string) (*StickerSet, error) {
 	return resp.Result, nil
 }
 
-// CreateStickerSet creates a new sticker set.
+// CreateStickerSet creates a new sticker set with
+// the stickers of s.Input, up to MaxStickersInSet.
 func (b *Bot) CreateStickerSet(to Recipient, s StickerSet) error {
-	files := make(map[string]File)
-	if s.PNG != nil {
-		files["png_sticker"] = *s.PNG
-	}
-	if s.TGS != nil {
-		files["tgs_sticker"] = *s.TGS
+	stickers := s.inputStickers()
+	if len(stickers) == 0 || len(stickers) > MaxStickersInSet {
+		return fmt.Errorf("telebot: sticker set must have 1-%d stickers", MaxStickersInSet)
 	}
-	if s.WebM != nil {
-		files["webm_sticker"] = *s.WebM
+
+	files := make(map[string]File)
+	input := make([]inputSticker, len(stickers))
+	for i, sticker := range stickers {
+		in, err := b.inputSticker(i, sticker, files)
+		if err != nil {
+			return err
+		}
+		input[i] = in
 	}
+	data, _ := b.json.Marshal(input)
 
 	params := map[string]any{
-		"user_id":      to.Recipient(),
-		"sticker_type": s.Type,
-		"name":         s.Name,
-		"title":        s.Title,
-		"emojis":       s.Emojis,
+		"user_id":  to.Recipient(),
+		"name":     s.Name,
+		"title":    s.Title,
+		"stickers": unsafeConvert.StringPointer(data),
 	}
-
-	if s.MaskPosition != nil {
-		data, _ := b.json.Marshal(&s.MaskPosition)
-		params["mask_position"] = unsafeConvert.StringPointer(data)
+	if s.Type != "" {
+		params["sticker_type"] = s.Type
+	}
+	if s.NeedsRepainting {
+		params["needs_repainting"] = "true"
 	}
 
 	r, err := b.sendFiles("createNewStickerSet", files, params)
@@ -125,25 +282,29 @@ func (b *Bot) DeleteStickerSet(name string) error {
 }
 
 // AddSticker adds a new sticker to the existing sticker set.
+//
+// Deprecated: use AddStickerToSet instead.
 func (b *Bot) AddSticker(to Recipient, s StickerSet) error {
+	stickers := s.inputStickers()
+	if len(stickers) == 0 {
+		return fmt.Errorf("telebot: sticker set must have 1-%d stickers", MaxStickersInSet)
+	}
+	return b.AddStickerToSet(to, s.Name, stickers[0])
+}
+
+// AddStickerToSet adds a new sticker to the existing sticker set.
+func (b *Bot) AddStickerToSet(to Recipient, name string, sticker InputSticker) error {
 	files := make(map[string]File)
-	if s.PNG != nil {
-		files["png_sticker"] = *s.PNG
-	} else if s.TGS != nil {
-		files["tgs_sticker"] = *s.TGS
-	} else if s.WebM != nil {
-		files["webm_sticker"] = *s.WebM
+	in, err := b.inputSticker(0, sticker, files)
+	if err != nil {
+		return err
 	}
+	data, _ := b.json.Marshal(in)
 
 	params := map[string]any{
 		"user_id": to.Recipient(),
-		"name":    s.Name,
-		"emojis":  s.Emojis,
-	}
-
-	if s.MaskPosition != nil {
-		data, _ := b.json.Marshal(&s.MaskPosition)
-		params["mask_position"] = unsafeConvert.StringPointer(data)
+		"name":    name,
+		"sticker": unsafeConvert.StringPointer(data),
 	}
 
 	r, err := b.sendFiles("addStickerToSet", files, params)
@@ -168,6 +329,78 @@ func (b *Bot) DeleteSticker(sticker string) error {
 	return err
 }
 
+// SetStickerEmojis changes the list of emojis assigned
+// to the regular or custom emoji sticker.
+func (b *Bot) SetStickerEmojis(sticker string, emojis []string) error {
+	params := map[string]any{
+		"sticker":    sticker,
+		"emoji_list": emojis,
+	}
+
+	r, err := b.Raw("setStickerEmojiList", params)
+	ReleaseBuffer(r)
+	return err
+}
+
+// SetStickerKeywords changes the search keywords assigned
+// to the regular or custom emoji sticker.
+func (b *Bot) SetStickerKeywords(sticker string, keywords []string) error {
+	if keywords == nil {
+		keywords = []string{}
+	}
+	params := map[string]any{
+		"sticker":  sticker,
+		"keywords": keywords,
+	}
+
+	r, err := b.Raw("setStickerKeywords", params)
+	ReleaseBuffer(r)
+	return err
+}
+
+// SetStickerMaskPosition changes the mask position of the mask sticker.
+// Nil position removes the mask position.
+func (b *Bot) SetStickerMaskPosition(sticker string, mask *MaskPosition) error {
+	params := map[string]any{
+		"sticker": sticker,
+	}
+	if mask != nil {
+		params["mask_position"] = mask
+	}
+
+	r, err := b.Raw("setStickerMaskPosition", params)
+	ReleaseBuffer(r)
+	return err
+}
+
+// SetStickerSetTitle sets the title of the sticker set created by the bot.
+func (b *Bot) SetStickerSetTitle(name, title string) error {
+	params := map[string]string{
+		"name":  name,
+		"title": title,
+	}
+
+	r, err := b.Raw("setStickerSetTitle", params)
+	ReleaseBuffer(r)
+	return err
+}
+
+// SetCustomEmojiStickerSetThumbnail sets the custom emoji as the
+// thumbnail of the custom emoji sticker set. Empty customEmojiID
+// drops the thumbnail, and the first sticker is used instead.
+func (b *Bot) SetCustomEmojiStickerSetThumbnail(name, customEmojiID string) error {
+	params := map[string]string{
+		"name": name,
+	}
+	if customEmojiID != "" {
+		params["custom_emoji_id"] = customEmojiID
+	}
+
+	r, err := b.Raw("setCustomEmojiStickerSetThumbnail", params)
+	ReleaseBuffer(r)
+	return err
+}
+
 // SetStickerSetThumbnail sets a thumbnail of the sticker set.
 // Animated thumbnails can be set for animated sticker sets only.
 //
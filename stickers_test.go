@@ -0,0 +1,83 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitEmojis(t *testing.T) {
+	assert.Nil(t, splitEmojis(""))
+	assert.Equal(t, []string{"😀"}, splitEmojis("😀"))
+	assert.Equal(t, []string{"😀", "❤️", "👍🏽"}, splitEmojis("😀❤️👍🏽"))
+	assert.Equal(t, []string{"👨‍👩‍👧", "🇺🇦", "🇯🇵"}, splitEmojis("👨‍👩‍👧🇺🇦🇯🇵"))
+	assert.Equal(t, []string{"1️⃣", "🙂"}, splitEmojis("1️⃣ 🙂"))
+}
+
+func TestStickerSets(t *testing.T) {
+	params := make(map[string]map[string]any)
+	b := newFakeBot(t, Settings{}, func(w http.ResponseWriter, r *http.Request) {
+		method := fakeMethod(r)
+		var p map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		params[method] = p
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	user := &User{ID: 1}
+
+	err := b.CreateStickerSet(user, StickerSet{Name: "set_by_bot", Title: "Set"})
+	assert.Error(t, err)
+
+	require.NoError(t, b.CreateStickerSet(user, StickerSet{
+		Name:  "set_by_bot",
+		Title: "Set",
+		Type:  StickerMask,
+		Input: []InputSticker{
+			{Sticker: File{FileID: "a"}, Format: StickerStatic, Emojis: []string{"😀"}, Keywords: []string{"smile"}},
+			{
+				Sticker:      File{FileURL: "https://example.com/b.png"},
+				Format:       StickerStatic,
+				Emojis:       []string{"🎭"},
+				MaskPosition: &MaskPosition{Feature: FeatureEyes, Scale: 1},
+			},
+		},
+	}))
+	p := params["createNewStickerSet"]
+	assert.Equal(t, "mask", p["sticker_type"])
+	var stickers []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(p["stickers"].(string)), &stickers))
+	require.Len(t, stickers, 2)
+	assert.Equal(t, "a", stickers[0]["sticker"])
+	assert.Equal(t, []any{"smile"}, stickers[0]["keywords"])
+	assert.Nil(t, stickers[0]["mask_position"])
+	assert.Equal(t, "https://example.com/b.png", stickers[1]["sticker"])
+	assert.Equal(t, "eyes", stickers[1]["mask_position"].(map[string]any)["point"])
+
+	require.NoError(t, b.AddSticker(user, StickerSet{Name: "set_by_bot", Emojis: "😀🙂", TGS: &File{FileID: "c"}}))
+	var sticker map[string]any
+	require.NoError(t, json.Unmarshal([]byte(params["addStickerToSet"]["sticker"].(string)), &sticker))
+	assert.Equal(t, map[string]any{
+		"sticker":    "c",
+		"format":     "animated",
+		"emoji_list": []any{"😀", "🙂"},
+	}, sticker)
+
+	require.NoError(t, b.SetStickerEmojis("c", []string{"🙂"}))
+	assert.Equal(t, []any{"🙂"}, params["setStickerEmojiList"]["emoji_list"])
+
+	require.NoError(t, b.SetStickerKeywords("c", nil))
+	assert.Equal(t, []any{}, params["setStickerKeywords"]["keywords"])
+
+	require.NoError(t, b.SetStickerMaskPosition("c", nil))
+	assert.NotContains(t, params["setStickerMaskPosition"], "mask_position")
+
+	require.NoError(t, b.SetStickerSetTitle("set_by_bot", "New"))
+	assert.Equal(t, "New", params["setStickerSetTitle"]["title"])
+
+	require.NoError(t, b.SetCustomEmojiStickerSetThumbnail("set_by_bot", ""))
+	assert.Equal(t, map[string]any{"name": "set_by_bot"}, params["setCustomEmojiStickerSetThumbnail"])
+}
@@ -0,0 +1,50 @@
+// Command stickersync syncs a sticker set with a directory:
+//
+//	TOKEN=... stickersync -owner 123456 -dir stickers/pack
+//
+// See the stickersync package for the layout of the directory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	tele "github.com/3JoB/telebot/v2"
+	"github.com/3JoB/telebot/v2/stickersync"
+)
+
+func main() {
+	var (
+		dir    = flag.String("dir", ".", "directory with the manifest and sticker files")
+		owner  = flag.Int64("owner", 0, "user ID of the sticker set owner")
+		dryRun = flag.Bool("dry-run", false, "print the operations without applying them")
+	)
+	flag.Parse()
+
+	token := os.Getenv("TOKEN")
+	if token == "" || *owner == 0 {
+		fmt.Fprintln(os.Stderr, "TOKEN environment variable and -owner flag are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	b, err := tele.NewBot(tele.Settings{Token: token})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var ops []stickersync.Op
+	if *dryRun {
+		ops, err = stickersync.Plan(b, *dir)
+	} else {
+		ops, err = stickersync.Sync(b, &tele.User{ID: *owner}, *dir)
+	}
+	for _, op := range ops {
+		fmt.Println(op)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
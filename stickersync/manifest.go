@@ -0,0 +1,163 @@
+package stickersync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+const (
+	// ManifestFile is the name of the manifest in the directory.
+	ManifestFile = "stickers.yml"
+
+	// LockFile is the name of the file, which keeps the state of
+	// the uploaded stickers. Commit it along with the stickers.
+	LockFile = "stickers.lock"
+)
+
+// Manifest describes the sticker set kept in the directory:
+//
+//	name: pack_by_mybot
+//	title: My Pack
+//	stickers:
+//	  - file: hello.webp
+//	    emoji: [👋]
+//	    keywords: [hello, hi]
+//	  - file: bye.tgs
+//	    emoji: [😢, 👋]
+//
+// The order of the stickers is the order of the set.
+type Manifest struct {
+	Name     string              `yaml:"name"`
+	Title    string              `yaml:"title"`
+	Type     tele.StickerSetType `yaml:"type"`
+	Stickers []Sticker           `yaml:"stickers"`
+
+	dir string
+}
+
+// Sticker is a sticker of the manifest.
+type Sticker struct {
+	// File is the path of the sticker file relative to the directory.
+	// The format is detected by the extension: .png and .webp are
+	// static, .tgs are animated and .webm are video stickers.
+	File     string   `yaml:"file"`
+	Emojis   []string `yaml:"emoji"`
+	Keywords []string `yaml:"keywords"`
+
+	hash string
+}
+
+// Load reads the manifest from the directory.
+func Load(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{dir: dir}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Name == "" || m.Title == "" {
+		return nil, errors.New("stickersync: manifest must have name and title")
+	}
+
+	seen := make(map[string]bool, len(m.Stickers))
+	for i := range m.Stickers {
+		s := &m.Stickers[i]
+		if seen[s.File] {
+			return nil, errors.New("stickersync: duplicate sticker " + s.File)
+		}
+		seen[s.File] = true
+
+		if format(s.File) == "" {
+			return nil, errors.New("stickersync: unknown format of " + s.File)
+		}
+		if len(s.Emojis) == 0 {
+			return nil, errors.New("stickersync: no emoji for " + s.File)
+		}
+		if s.hash, err = hashFile(filepath.Join(dir, s.File)); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Manifest) input(s Sticker) tele.InputSticker {
+	return tele.InputSticker{
+		Sticker:  tele.FromDisk(filepath.Join(m.dir, s.File)),
+		Format:   format(s.File),
+		Emojis:   s.Emojis,
+		Keywords: s.Keywords,
+	}
+}
+
+func format(file string) tele.StickerFormat {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".png", ".webp":
+		return tele.StickerStatic
+	case ".tgs":
+		return tele.StickerAnimated
+	case ".webm":
+		return tele.StickerVideo
+	}
+	return ""
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Lock is the state of the uploaded stickers by their files.
+type Lock map[string]LockedSticker
+
+// LockedSticker is the uploaded sticker.
+type LockedSticker struct {
+	Hash     string   `yaml:"hash"`
+	UniqueID string   `yaml:"unique_id"`
+	Emojis   []string `yaml:"emoji"`
+	Keywords []string `yaml:"keywords,omitempty"`
+}
+
+// LoadLock reads the lock from the directory.
+// A missing lock file results in an empty lock.
+func LoadLock(dir string) (Lock, error) {
+	data, err := os.ReadFile(filepath.Join(dir, LockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return Lock{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lock := Lock{}
+	return lock, yaml.Unmarshal(data, &lock)
+}
+
+// Save writes the lock to the directory.
+func (l Lock) Save(dir string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, LockFile), data, 0o644)
+}
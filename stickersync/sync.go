@@ -0,0 +1,412 @@
+// Package stickersync keeps a sticker set in sync with a directory.
+//
+// The directory holds the sticker files and the manifest (see Manifest),
+// which lists them in order with their emoji and keywords. Sync compares
+// the directory with the remote set and issues the minimal sequence of
+// calls making them equal: it deletes stickers, which are gone or
+// changed, adds the new ones, moves the misplaced ones and updates emoji
+// and keywords. The uploaded stickers are tracked in the lock file, which
+// should be kept along with the manifest.
+//
+//	ops, err := stickersync.Sync(b, owner, "stickers/pack")
+package stickersync
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+// API is the part of the bot API used to sync
+// sticker sets, which *tele.Bot implements.
+type API interface {
+	StickerSet(name string) (*tele.StickerSet, error)
+	CreateStickerSet(to tele.Recipient, s tele.StickerSet) error
+	AddStickerToSet(to tele.Recipient, name string, sticker tele.InputSticker) error
+	DeleteSticker(sticker string) error
+	SetStickerPosition(sticker string, position int) error
+	SetStickerEmojis(sticker string, emojis []string) error
+	SetStickerKeywords(sticker string, keywords []string) error
+	SetStickerSetTitle(name, title string) error
+}
+
+// OpKind is the kind of the operation.
+type OpKind int
+
+const (
+	OpCreate OpKind = iota
+	OpDelete
+	OpAdd
+	OpMove
+	OpEmojis
+	OpKeywords
+	OpTitle
+)
+
+// Op is an operation changing the remote sticker set.
+type Op struct {
+	Kind OpKind
+
+	// File is the sticker file, if it's known.
+	File string
+
+	// Files are the sticker files the set is created with.
+	Files []string
+
+	// Sticker is the file ID of the deleted sticker,
+	// or the moved one, which is unknown to the lock.
+	Sticker string
+
+	// Position is the new position of the moved sticker.
+	Position int
+
+	// Values are the new emoji or keywords of the sticker.
+	Values []string
+
+	// Title is the new title of the set.
+	Title string
+}
+
+func (op Op) String() string {
+	switch op.Kind {
+	case OpCreate:
+		return "create the set with " + strings.Join(op.Files, ", ")
+	case OpDelete:
+		if op.File != "" {
+			return "delete " + op.File
+		}
+		return "delete " + op.Sticker
+	case OpAdd:
+		return "add " + op.File
+	case OpMove:
+		if op.File != "" {
+			return "move " + op.File + " to " + strconv.Itoa(op.Position)
+		}
+		return "move " + op.Sticker + " to " + strconv.Itoa(op.Position)
+	case OpEmojis:
+		return "set emoji of " + op.File + " to " + strings.Join(op.Values, " ")
+	case OpKeywords:
+		return "set keywords of " + op.File + " to " + strings.Join(op.Values, ", ")
+	case OpTitle:
+		return "set title to " + op.Title
+	}
+	return "unknown operation"
+}
+
+// Plan returns the operations, which Sync would apply.
+func Plan(api API, dir string) ([]Op, error) {
+	m, lock, remote, err := load(api, dir)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(m, lock, remote), nil
+}
+
+// Sync makes the remote sticker set match the directory, creating
+// the set if it doesn't exist, and returns the applied operations.
+// The lock file is updated even if an operation fails, so the
+// next call continues from the failed one.
+func Sync(api API, owner tele.Recipient, dir string) (ops []Op, err error) {
+	m, lock, remote, err := load(api, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ops = Diff(m, lock, remote)
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	defer func() {
+		if lerr := lock.Save(dir); err == nil {
+			err = lerr
+		}
+	}()
+
+	s := &syncer{api: api, owner: owner, m: m, lock: lock, ids: make(map[string]string)}
+	if remote != nil {
+		byUnique := make(map[string]string, len(remote.Stickers))
+		for _, st := range remote.Stickers {
+			byUnique[st.UniqueID] = st.FileID
+		}
+		for file, locked := range lock {
+			if id, ok := byUnique[locked.UniqueID]; ok {
+				s.ids[file] = id
+			}
+		}
+	}
+
+	for i, op := range ops {
+		if err := s.apply(op); err != nil {
+			return ops[:i], err
+		}
+	}
+	return ops, nil
+}
+
+func load(api API, dir string) (*Manifest, Lock, *tele.StickerSet, error) {
+	m, err := Load(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	lock, err := LoadLock(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	remote, err := api.StickerSet(m.Name)
+	if errors.Is(err, tele.ErrStickerSetInvalid) {
+		return m, lock, nil, nil
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return m, lock, remote, nil
+}
+
+// Diff returns the operations making the remote sticker set, which is
+// nil if it doesn't exist, match the manifest. Remote stickers are
+// matched with the files by the lock. Only the stickers the lock knows
+// are deleted, so the ones added by other means, or synced with a lost
+// lock, are kept after the synced ones.
+func Diff(m *Manifest, lock Lock, remote *tele.StickerSet) (ops []Op) {
+	if remote == nil {
+		n := min(len(m.Stickers), tele.MaxStickersInSet)
+		create := Op{Kind: OpCreate}
+		for _, s := range m.Stickers[:n] {
+			create.Files = append(create.Files, s.File)
+		}
+		ops = append(ops, create)
+		for _, s := range m.Stickers[n:] {
+			ops = append(ops, Op{Kind: OpAdd, File: s.File})
+		}
+		return ops
+	}
+
+	local := make(map[string]Sticker, len(m.Stickers))
+	for _, s := range m.Stickers {
+		local[s.File] = s
+	}
+	byUnique := make(map[string]string, len(lock))
+	for file, locked := range lock {
+		byUnique[locked.UniqueID] = file
+	}
+
+	// current is the order of the remote set after deletions and additions.
+	var current, unknown []string
+	kept := make(map[string]bool)
+	for _, st := range remote.Stickers {
+		file, ok := byUnique[st.UniqueID]
+		if !ok {
+			current = append(current, unknownPrefix+st.FileID)
+			unknown = append(unknown, unknownPrefix+st.FileID)
+			continue
+		}
+		if s, exists := local[file]; exists && !kept[file] && lock[file].Hash == s.hash {
+			kept[file] = true
+			current = append(current, file)
+			continue
+		}
+		ops = append(ops, Op{Kind: OpDelete, File: file, Sticker: st.FileID})
+	}
+	for _, s := range m.Stickers {
+		if !kept[s.File] {
+			ops = append(ops, Op{Kind: OpAdd, File: s.File})
+			current = append(current, s.File)
+		}
+	}
+
+	desired := make([]string, 0, len(m.Stickers)+len(unknown))
+	for _, s := range m.Stickers {
+		desired = append(desired, s.File)
+	}
+	ops = append(ops, moves(current, append(desired, unknown...))...)
+
+	for _, s := range m.Stickers {
+		if !kept[s.File] {
+			continue
+		}
+		if locked := lock[s.File]; !slices.Equal(locked.Emojis, s.Emojis) {
+			ops = append(ops, Op{Kind: OpEmojis, File: s.File, Values: s.Emojis})
+		}
+		if locked := lock[s.File]; !slices.Equal(locked.Keywords, s.Keywords) {
+			ops = append(ops, Op{Kind: OpKeywords, File: s.File, Values: s.Keywords})
+		}
+	}
+
+	if remote.Title != m.Title {
+		ops = append(ops, Op{Kind: OpTitle, Title: m.Title})
+	}
+	return ops
+}
+
+// moves returns the minimal moves reordering the current stickers to
+// the desired order: the stickers of the longest subsequence, which is
+// already in order, stay, and every other one is moved right after its
+// predecessor in the desired order.
+func moves(current, desired []string) (ops []Op) {
+	index := make(map[string]int, len(desired))
+	for i, file := range desired {
+		index[file] = i
+	}
+
+	inOrder := make(map[string]bool)
+	for _, i := range longestIncreasing(current, index) {
+		inOrder[current[i]] = true
+	}
+
+	order := slices.Clone(current)
+	for i, file := range desired {
+		if inOrder[file] {
+			continue
+		}
+
+		from := slices.Index(order, file)
+		to := 0
+		if i > 0 {
+			to = slices.Index(order, desired[i-1])
+			if from > to {
+				to++
+			}
+		}
+
+		if from != to {
+			order = slices.Insert(slices.Delete(order, from, from+1), to, file)
+			if id, ok := strings.CutPrefix(file, unknownPrefix); ok {
+				ops = append(ops, Op{Kind: OpMove, Sticker: id, Position: to})
+			} else {
+				ops = append(ops, Op{Kind: OpMove, File: file, Position: to})
+			}
+		}
+	}
+	return ops
+}
+
+// unknownPrefix marks the remote stickers unknown to the
+// lock among the files, followed by their file IDs.
+const unknownPrefix = "\x00"
+
+// longestIncreasing returns the indexes of the longest subsequence
+// of the files, which desired indexes are increasing.
+func longestIncreasing(files []string, index map[string]int) []int {
+	var (
+		tails = make([]int, 0, len(files)) // indexes of the smallest tails
+		prev  = make([]int, len(files))
+	)
+	for i, file := range files {
+		n, _ := slices.BinarySearchFunc(tails, index[file], func(j, target int) int {
+			return index[files[j]] - target
+		})
+		prev[i] = -1
+		if n > 0 {
+			prev[i] = tails[n-1]
+		}
+		if n == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[n] = i
+		}
+	}
+
+	seq := make([]int, len(tails))
+	for i, j := len(tails)-1, -1; i >= 0; i-- {
+		if j == -1 {
+			j = tails[i]
+		} else {
+			j = prev[j]
+		}
+		seq[i] = j
+	}
+	return seq
+}
+
+type syncer struct {
+	api   API
+	owner tele.Recipient
+	m     *Manifest
+	lock  Lock
+	ids   map[string]string // file IDs of the stickers by their files
+}
+
+func (s *syncer) apply(op Op) error {
+	switch op.Kind {
+	case OpCreate:
+		set := tele.StickerSet{Name: s.m.Name, Title: s.m.Title, Type: s.m.Type}
+		for _, file := range op.Files {
+			set.Input = append(set.Input, s.m.input(s.sticker(file)))
+		}
+		if err := s.api.CreateStickerSet(s.owner, set); err != nil {
+			return err
+		}
+		return s.uploaded(op.Files...)
+	case OpDelete:
+		if err := s.api.DeleteSticker(op.Sticker); err != nil {
+			return err
+		}
+		if op.File != "" {
+			delete(s.lock, op.File)
+			delete(s.ids, op.File)
+		}
+	case OpAdd:
+		if err := s.api.AddStickerToSet(s.owner, s.m.Name, s.m.input(s.sticker(op.File))); err != nil {
+			return err
+		}
+		return s.uploaded(op.File)
+	case OpMove:
+		id := op.Sticker
+		if op.File != "" {
+			id = s.ids[op.File]
+		}
+		return s.api.SetStickerPosition(id, op.Position)
+	case OpEmojis:
+		if err := s.api.SetStickerEmojis(s.ids[op.File], op.Values); err != nil {
+			return err
+		}
+		locked := s.lock[op.File]
+		locked.Emojis = op.Values
+		s.lock[op.File] = locked
+	case OpKeywords:
+		if err := s.api.SetStickerKeywords(s.ids[op.File], op.Values); err != nil {
+			return err
+		}
+		locked := s.lock[op.File]
+		locked.Keywords = op.Values
+		s.lock[op.File] = locked
+	case OpTitle:
+		return s.api.SetStickerSetTitle(s.m.Name, op.Title)
+	}
+	return nil
+}
+
+func (s *syncer) sticker(file string) Sticker {
+	i := slices.IndexFunc(s.m.Stickers, func(s Sticker) bool {
+		return s.File == file
+	})
+	return s.m.Stickers[i]
+}
+
+// uploaded locks the files, which are the last stickers of the set.
+func (s *syncer) uploaded(files ...string) error {
+	set, err := s.api.StickerSet(s.m.Name)
+	if err != nil {
+		return err
+	}
+	if len(set.Stickers) < len(files) {
+		return errors.New("stickersync: uploaded stickers are missing in the set")
+	}
+
+	stickers := set.Stickers[len(set.Stickers)-len(files):]
+	for i, file := range files {
+		st := s.sticker(file)
+		s.ids[file] = stickers[i].FileID
+		s.lock[file] = LockedSticker{
+			Hash:     st.hash,
+			UniqueID: stickers[i].UniqueID,
+			Emojis:   st.Emojis,
+			Keywords: st.Keywords,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package stickersync
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "github.com/3JoB/telebot/v2"
+)
+
+// fakeAPI keeps the sticker set in memory.
+type fakeAPI struct {
+	set   *tele.StickerSet
+	files map[string]string // local files by unique IDs
+	seq   int
+	calls []string
+}
+
+func (f *fakeAPI) StickerSet(name string) (*tele.StickerSet, error) {
+	if f.set == nil {
+		return nil, tele.ErrStickerSetInvalid
+	}
+	set := *f.set
+	set.Stickers = slices.Clone(f.set.Stickers)
+	return &set, nil
+}
+
+func (f *fakeAPI) CreateStickerSet(to tele.Recipient, s tele.StickerSet) error {
+	f.calls = append(f.calls, "create")
+	f.set = &tele.StickerSet{Name: s.Name, Title: s.Title}
+	for _, in := range s.Input {
+		f.add(in)
+	}
+	return nil
+}
+
+func (f *fakeAPI) AddStickerToSet(to tele.Recipient, name string, in tele.InputSticker) error {
+	f.calls = append(f.calls, "add "+filepath.Base(in.Sticker.FileLocal))
+	f.add(in)
+	return nil
+}
+
+func (f *fakeAPI) add(in tele.InputSticker) {
+	f.seq++
+	id := strconv.Itoa(f.seq)
+	f.files["u"+id] = filepath.Base(in.Sticker.FileLocal)
+	f.set.Stickers = append(f.set.Stickers, tele.Sticker{
+		File:  tele.File{FileID: "f" + id, UniqueID: "u" + id},
+		Emoji: in.Emojis[0],
+	})
+}
+
+func (f *fakeAPI) index(sticker string) int {
+	return slices.IndexFunc(f.set.Stickers, func(s tele.Sticker) bool {
+		return s.FileID == sticker
+	})
+}
+
+func (f *fakeAPI) DeleteSticker(sticker string) error {
+	f.calls = append(f.calls, "delete "+sticker)
+	i := f.index(sticker)
+	f.set.Stickers = slices.Delete(f.set.Stickers, i, i+1)
+	return nil
+}
+
+func (f *fakeAPI) SetStickerPosition(sticker string, position int) error {
+	f.calls = append(f.calls, "move "+sticker+" "+strconv.Itoa(position))
+	i := f.index(sticker)
+	s := f.set.Stickers[i]
+	f.set.Stickers = slices.Insert(slices.Delete(f.set.Stickers, i, i+1), position, s)
+	return nil
+}
+
+func (f *fakeAPI) SetStickerEmojis(sticker string, emojis []string) error {
+	f.calls = append(f.calls, "emoji "+sticker)
+	f.set.Stickers[f.index(sticker)].Emoji = emojis[0]
+	return nil
+}
+
+func (f *fakeAPI) SetStickerKeywords(sticker string, keywords []string) error {
+	f.calls = append(f.calls, "keywords "+sticker)
+	return nil
+}
+
+func (f *fakeAPI) SetStickerSetTitle(name, title string) error {
+	f.calls = append(f.calls, "title")
+	f.set.Title = title
+	return nil
+}
+
+// order returns the local files of the remote set.
+func (f *fakeAPI) order() (files []string) {
+	for _, s := range f.set.Stickers {
+		files = append(files, f.files[s.UniqueID])
+	}
+	return files
+}
+
+func write(t *testing.T, dir, name, data string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644))
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	owner := &tele.User{ID: 1}
+	api := &fakeAPI{files: make(map[string]string)}
+
+	for _, name := range []string{"a.png", "b.webp", "c.tgs", "d.webm"} {
+		write(t, dir, name, name)
+	}
+	write(t, dir, ManifestFile, `
+name: pack_by_bot
+title: Pack
+stickers:
+  - {file: a.png, emoji: [😀]}
+  - {file: b.webp, emoji: [😃], keywords: [smile]}
+  - {file: c.tgs, emoji: [😄]}
+`)
+
+	ops, err := Sync(api, owner, dir)
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	assert.Equal(t, "create the set with a.png, b.webp, c.tgs", ops[0].String())
+	assert.Equal(t, []string{"a.png", "b.webp", "c.tgs"}, api.order())
+
+	lock, err := LoadLock(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "u2", lock["b.webp"].UniqueID)
+	assert.Equal(t, []string{"smile"}, lock["b.webp"].Keywords)
+
+	ops, err = Sync(api, owner, dir)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	write(t, dir, "a.png", "changed")
+	write(t, dir, ManifestFile, `
+name: pack_by_bot
+title: New Pack
+stickers:
+  - {file: c.tgs, emoji: [😄, 😆]}
+  - {file: d.webm, emoji: [🙂]}
+  - {file: a.png, emoji: [😀]}
+`)
+
+	api.calls = nil
+	ops, err = Plan(api, dir)
+	require.NoError(t, err)
+	var plan []string
+	for _, op := range ops {
+		plan = append(plan, op.String())
+	}
+	assert.Equal(t, []string{
+		"delete a.png",
+		"delete b.webp",
+		"add d.webm",
+		"add a.png",
+		"set emoji of c.tgs to 😄 😆",
+		"set title to New Pack",
+	}, plan)
+	assert.Empty(t, api.calls)
+
+	_, err = Sync(api, owner, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"delete f1", "delete f2", "add d.webm", "add a.png", "emoji f3", "title"}, api.calls)
+	assert.Equal(t, []string{"c.tgs", "d.webm", "a.png"}, api.order())
+
+	lock, err = LoadLock(dir)
+	require.NoError(t, err)
+	assert.Len(t, lock, 3)
+	assert.NotContains(t, lock, "b.webp")
+
+	ops, err = Sync(api, owner, dir)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	// Stickers unknown to the lock are kept after the synced ones.
+	api.set.Stickers = slices.Insert(api.set.Stickers, 0, tele.Sticker{File: tele.File{FileID: "fx", UniqueID: "ux"}})
+	api.files["ux"] = "x"
+	api.calls = nil
+	_, err = Sync(api, owner, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"move fx 3"}, api.calls)
+	assert.Equal(t, []string{"c.tgs", "d.webm", "a.png", "x"}, api.order())
+
+	// Nothing is deleted without the lock.
+	require.NoError(t, os.Remove(filepath.Join(dir, LockFile)))
+	ops, err = Plan(api, dir)
+	require.NoError(t, err)
+	for _, op := range ops {
+		assert.NotEqual(t, OpDelete, op.Kind, op.String())
+	}
+}
+
+func TestMoves(t *testing.T) {
+	assert.Empty(t, moves([]string{"a", "b", "c"}, []string{"a", "b", "c"}))
+	assert.Equal(t, []Op{{Kind: OpMove, File: "d", Position: 0}},
+		moves([]string{"a", "b", "c", "d"}, []string{"d", "a", "b", "c"}))
+	assert.Equal(t, []Op{{Kind: OpMove, File: "a", Position: 3}},
+		moves([]string{"a", "b", "c", "d"}, []string{"b", "c", "d", "a"}))
+	assert.Equal(t, []Op{{Kind: OpMove, Sticker: "x", Position: 1}},
+		moves([]string{unknownPrefix + "x", "a"}, []string{"a", unknownPrefix + "x"}))
+
+	current := []string{"e", "c", "a", "d", "b"}
+	ops := moves(current, []string{"a", "b", "c", "d", "e"})
+	assert.Len(t, ops, 3)
+	for _, op := range ops {
+		i := slices.Index(current, op.File)
+		current = slices.Insert(slices.Delete(current, i, i+1), op.Position, op.File)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, current)
+}
@@ -41,6 +41,10 @@ var (
 	ErrCouldNotUpdate  = errors.New("telebot: could not fetch new updates")
 	ErrTrueResult      = errors.New("telebot: result is True")
 	ErrBadContext      = errors.New("telebot: context does not contain message")
+
+	ErrCallbackDataExpired = errors.New("telebot: callback data is not found in the store")
+	ErrCallbackForged      = errors.New("telebot: callback data signature is invalid")
+	ErrCallbackExpired     = errors.New("telebot: callback data is expired")
 )
 
 const DefaultApiURL = "https://api.telegram.org"
@@ -98,6 +102,7 @@ const (
 	OnMigration = "\amigration"
 
 	OnMedia           = "\amedia"
+	OnAlbum           = "\aalbum"
 	OnCallback        = "\acallback"
 	OnQuery           = "\aquery"
 	OnInlineResult    = "\ainline_result"
@@ -0,0 +1,90 @@
+package telebot
+
+import "sync"
+
+// Tracer starts spans. Implement it to plug in OpenTelemetry or any
+// other tracing library without making telebot depend on it.
+//
+// A span named SpanUpdate is started for every processed update.
+// It ends when the handler of the matched endpoint is finished.
+// Every Bot API request gets its own span named after the method,
+// which is a child of the update span when requested through the
+// helpers of the handler context (c.Send, c.Edit and so on).
+type Tracer interface {
+	// Start starts a new span. The parent is nil for root spans.
+	Start(parent Span, name string) Span
+}
+
+// Span represents a single traced operation.
+type Span interface {
+	SetAttribute(key string, value any)
+	SetError(err error)
+	End()
+}
+
+// SpanUpdate is the name of spans started by ProcessUpdate.
+const SpanUpdate = "telebot.update"
+
+// Attributes set on spans.
+const (
+	AttrUpdateID   = "telebot.update.id"
+	AttrUpdateType = "telebot.update.type"
+	AttrChatID     = "telebot.chat.id"
+	AttrEndpoint   = "telebot.endpoint"
+	AttrMethod     = "telebot.method"
+	AttrStatus     = "http.status_code"
+)
+
+// updateSpan wraps the update span, making it safe to end
+// by every handler dispatched for the same update.
+type updateSpan struct {
+	Span
+	once       sync.Once
+	dispatched bool
+}
+
+func (s *updateSpan) End() {
+	s.once.Do(s.Span.End)
+}
+
+// startUpdateSpan starts the span of
+// the update and binds it to the context.
+func (b *Bot) startUpdateSpan(c *Context) *updateSpan {
+	span := &updateSpan{Span: b.tracer.Start(nil, SpanUpdate)}
+	span.SetAttribute(AttrUpdateID, c.u.ID)
+	span.SetAttribute(AttrUpdateType, c.u.Type())
+	if chat := c.Chat(); chat != nil {
+		span.SetAttribute(AttrChatID, chat.ID)
+	}
+
+	c.span = span
+	return span
+}
+
+// spanParam is the key of the parent span in the request params.
+// It's removed before the params are sent.
+const spanParam = "\aspan"
+
+// spanOption passes the parent span of the
+// requests through the send options.
+type spanOption struct {
+	Span
+}
+
+// takeSpan removes the parent span from the params and returns it.
+func takeSpan(params map[string]any) Span {
+	span, _ := params[spanParam].(Span)
+	delete(params, spanParam)
+	return span
+}
+
+// startRequestSpan starts the span of the Bot API request, which is
+// a child of the parent, or returns nil if the bot has no tracer.
+func (b *Bot) startRequestSpan(parent Span, method string) Span {
+	if b.tracer == nil {
+		return nil
+	}
+	span := b.tracer.Start(parent, method)
+	span.SetAttribute(AttrMethod, method)
+	return span
+}
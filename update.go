@@ -2,6 +2,7 @@ package telebot
 
 import (
 	"strings"
+	"time"
 )
 
 // Update object represents an incoming update.
@@ -24,11 +25,64 @@ type Update struct {
 	ChatJoinRequest   *ChatJoinRequest  `json:"chat_join_request,omitempty"`
 }
 
+// Type returns the type of the update, named the same way as
+// LongPoller.AllowedUpdates values, e.g. "message" or "callback_query".
+// Returns an empty string for the unknown updates.
+func (u Update) Type() string {
+	switch {
+	case u.Message != nil:
+		return "message"
+	case u.EditedMessage != nil:
+		return "edited_message"
+	case u.ChannelPost != nil:
+		return "channel_post"
+	case u.EditedChannelPost != nil:
+		return "edited_channel_post"
+	case u.Callback != nil:
+		return "callback_query"
+	case u.Query != nil:
+		return "inline_query"
+	case u.InlineResult != nil:
+		return "chosen_inline_result"
+	case u.ShippingQuery != nil:
+		return "shipping_query"
+	case u.PreCheckoutQuery != nil:
+		return "pre_checkout_query"
+	case u.Poll != nil:
+		return "poll"
+	case u.PollAnswer != nil:
+		return "poll_answer"
+	case u.MyChatMember != nil:
+		return "my_chat_member"
+	case u.ChatMember != nil:
+		return "chat_member"
+	case u.ChatJoinRequest != nil:
+		return "chat_join_request"
+	default:
+		return ""
+	}
+}
+
 // ProcessUpdate processes a single incoming update.
 // A started bot calls this function automatically.
+//
+// If Settings.Tracer is set, the update is traced with a span,
+// which ends once the matched handler is finished.
 func (b *Bot) ProcessUpdate(u Update) bool {
 	c := b.NewContext(u)
+	if b.tracer == nil {
+		return b.processUpdate(u, c)
+	}
 
+	span := b.startUpdateSpan(c)
+	handled := b.processUpdate(u, c)
+	if !span.dispatched {
+		span.End()
+	}
+	return handled
+}
+
+func (b *Bot) processUpdate(u Update, c *Context) bool {
 	if u.Message != nil {
 		m := u.Message
 
@@ -63,6 +117,9 @@ func (b *Bot) ProcessUpdate(u Update) bool {
 			return b.handle(OnText, c)
 		}
 
+		if m.AlbumID != "" && b.handleAlbum(c, m) {
+			return true
+		}
 		if b.handleMedia(c) {
 			return true
 		}
@@ -158,6 +215,9 @@ func (b *Bot) ProcessUpdate(u Update) bool {
 
 		if m.MigrateTo != 0 {
 			m.MigrateFrom = m.Chat.ID
+			if b.autoMigrate {
+				b.Migrate(m.MigrateFrom, m.MigrateTo)
+			}
 			return b.handle(OnMigration, c)
 		}
 
@@ -200,6 +260,9 @@ func (b *Bot) ProcessUpdate(u Update) bool {
 		if m.PinnedMessage != nil {
 			return b.handle(OnPinned, c)
 		}
+		if m.AlbumID != "" && b.handleAlbum(c, m) {
+			return true
+		}
 
 		return b.handle(OnChannelPost, c)
 	}
@@ -209,11 +272,28 @@ func (b *Bot) ProcessUpdate(u Update) bool {
 	}
 
 	if u.Callback != nil {
-		if data := u.Callback.Data; data != "" && data[0] == '\f' {
+		data, err := b.expandCallback(u.Callback.Data)
+		if err != nil {
+			b.rejectCallback(c, err)
+			return true
+		}
+
+		data, signed, err := b.verifyCallback(data)
+		if err != nil {
+			b.rejectCallback(c, err)
+			return true
+		}
+		u.Callback.Data = data
+
+		if data != "" && data[0] == '\f' {
 			match := cbackRx.FindAllStringSubmatch(data, -1)
 			if match != nil {
 				unique, payload := match[0][1], match[0][3]
 				if handler, ok := b.handlers["\f"+unique]; ok {
+					if handler.signed && !signed {
+						b.rejectCallback(c, ErrCallbackForged)
+						return true
+					}
 					u.Callback.Unique = unique
 					u.Callback.Data = payload
 					b.runHandler(handler, c)
@@ -307,12 +387,30 @@ func (b *Bot) handleMedia(c *Context) bool {
 }
 
 func (b *Bot) runHandler(h *Handle, c *Context) {
+	span := c.span
+	if span != nil {
+		span.dispatched = true
+		span.SetAttribute(AttrEndpoint, h.endpoint)
+	}
+
 	f := func() {
-		if err := h.doMiddleware(c); err != nil {
+		start := time.Now()
+		err := h.doMiddleware(c)
+		if err != nil {
 			b.OnError(err, c)
 		}
-		if err := h.do(c); err != nil {
-			b.OnError(err, c)
+		if herr := h.do(c); herr != nil {
+			b.OnError(herr, c)
+			err = herr
+		}
+		if b.observer != nil {
+			b.observer.OnHandle(c, h.endpoint, time.Since(start), err)
+		}
+		if span != nil {
+			if err != nil {
+				span.SetError(err)
+			}
+			span.End()
 		}
 		c.releaseContext()
 	}